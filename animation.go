@@ -0,0 +1,240 @@
+package banana
+
+import (
+	"time"
+
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// AnimationMode controls what an animation does after its last frame.
+type AnimationMode int
+
+const (
+	// AnimationLoop starts over from the first frame.
+	AnimationLoop AnimationMode = iota
+	// AnimationPingPong plays the frames backwards and then forwards again.
+	AnimationPingPong
+	// AnimationOnce stops on the last frame.
+	AnimationOnce
+)
+
+// AnimationFrame is a region shown for a duration.
+type AnimationFrame struct {
+	Region   Region
+	Duration time.Duration
+}
+
+// AnimationEvent names a frame of an animation. The player reports the name every time the frame is shown,
+// which is useful for footstep sounds or the frame of an attack that deals damage.
+type AnimationEvent struct {
+	Frame int
+	Name  string
+}
+
+// Animation is a named clip of frames. Animations hold no playback state,
+// so one animation can be shared by any number of players.
+type Animation struct {
+	Name   string
+	Frames []AnimationFrame
+	Mode   AnimationMode
+	Events []AnimationEvent
+}
+
+// NewAnimation returns a looping animation that shows every region for the same duration.
+func NewAnimation(name string, regions []Region, frameDuration time.Duration) *Animation {
+	frames := make([]AnimationFrame, len(regions))
+	for i, region := range regions {
+		frames[i] = AnimationFrame{Region: region, Duration: frameDuration}
+	}
+	return &Animation{Name: name, Frames: frames}
+}
+
+// AddEvent reports name whenever frame is shown.
+func (a *Animation) AddEvent(frame int, name string) {
+	a.Events = append(a.Events, AnimationEvent{Frame: frame, Name: name})
+}
+
+// Duration returns the time it takes to play every frame once.
+func (a *Animation) Duration() time.Duration {
+	var d time.Duration
+	for _, frame := range a.Frames {
+		d += frame.Duration
+	}
+	return d
+}
+
+// AnimationPlayer plays the animations of a sprite.
+// Call Update once from the update function so animations advance with the engine's ticks.
+type AnimationPlayer struct {
+	Sprite *Sprite
+	// Speed scales how fast time passes for the player. The zero value is treated as 1.
+	Speed float32
+	// OnEvent is called with the name of every event of a frame as the frame is shown.
+	OnEvent func(event string)
+	// OnFinished is called when an animation played with AnimationOnce reaches the end of its last frame.
+	OnFinished func(animation string)
+
+	animations map[string]*Animation
+	current    *Animation
+	frame      int
+	elapsed    time.Duration
+	backwards  bool
+	finished   bool
+}
+
+// NewAnimationPlayer returns a player that sets the region of sprite to the frame being shown.
+func NewAnimationPlayer(sprite *Sprite) *AnimationPlayer {
+	return &AnimationPlayer{
+		Sprite:     sprite,
+		animations: make(map[string]*Animation),
+	}
+}
+
+// Add makes animations playable by name.
+func (p *AnimationPlayer) Add(animations ...*Animation) {
+	for _, a := range animations {
+		p.animations[a.Name] = a
+	}
+}
+
+// Play switches to the named animation from its first frame.
+// Playing the animation that is already playing does nothing, so Play can be called every update.
+func (p *AnimationPlayer) Play(name string) {
+	if p.current != nil && p.current.Name == name {
+		return
+	}
+	p.restart(name)
+}
+
+// Restart plays the named animation from its first frame even if it is already playing.
+func (p *AnimationPlayer) Restart(name string) {
+	p.restart(name)
+}
+
+func (p *AnimationPlayer) restart(name string) {
+	a, ok := p.animations[name]
+	if !ok {
+		diag.Warnf("Animation %q not found", name)
+		return
+	}
+	if len(a.Frames) == 0 {
+		diag.Warnf("Animation %q has no frames", name)
+		return
+	}
+	p.current = a
+	p.frame = 0
+	p.elapsed = 0
+	p.backwards = false
+	p.finished = false
+	p.show()
+	p.fireEvents(a)
+}
+
+// Current returns the animation being played, or nil before the first Play.
+func (p *AnimationPlayer) Current() *Animation {
+	return p.current
+}
+
+// Frame returns the index of the frame being shown.
+func (p *AnimationPlayer) Frame() int {
+	return p.frame
+}
+
+// Finished reports whether an animation played with AnimationOnce has ended.
+func (p *AnimationPlayer) Finished() bool {
+	return p.finished
+}
+
+// Update advances the player by one engine tick.
+func (p *AnimationPlayer) Update() {
+	p.Advance(TickDuration)
+}
+
+// Advance moves the player forward by d, skipping frames if d spans more than one.
+func (p *AnimationPlayer) Advance(d time.Duration) {
+	a := p.current
+	if a == nil || p.finished {
+		return
+	}
+	if p.Speed != 0 {
+		d = time.Duration(float64(d) * float64(p.Speed))
+	}
+
+	p.elapsed += d
+	for {
+		duration := a.Frames[p.frame].Duration
+		if duration <= 0 {
+			duration = TickDuration
+		}
+		if p.elapsed < duration {
+			break
+		}
+		p.elapsed -= duration
+
+		if !p.step(a) {
+			return
+		}
+		p.show()
+		p.fireEvents(a)
+		// an event handler may have switched animations
+		if p.current != a {
+			return
+		}
+	}
+}
+
+// step moves to the next frame of a. It returns false when a has finished.
+func (p *AnimationPlayer) step(a *Animation) bool {
+	last := len(a.Frames) - 1
+	switch a.Mode {
+	case AnimationOnce:
+		if p.frame >= last {
+			p.finished = true
+			p.elapsed = 0
+			if p.OnFinished != nil {
+				p.OnFinished(a.Name)
+			}
+			return false
+		}
+		p.frame++
+	case AnimationPingPong:
+		if last == 0 {
+			return true
+		}
+		if p.backwards && p.frame == 0 || !p.backwards && p.frame == last {
+			p.backwards = !p.backwards
+		}
+		if p.backwards {
+			p.frame--
+		} else {
+			p.frame++
+		}
+	default:
+		p.frame = (p.frame + 1) % len(a.Frames)
+	}
+	return true
+}
+
+func (p *AnimationPlayer) show() {
+	if p.Sprite != nil {
+		p.Sprite.Region = p.current.Frames[p.frame].Region
+	}
+}
+
+func (p *AnimationPlayer) fireEvents(a *Animation) {
+	if p.OnEvent == nil {
+		return
+	}
+	for _, event := range a.Events {
+		if event.Frame == p.frame {
+			p.OnEvent(event.Name)
+		}
+	}
+}
+
+// Render draws the sprite of the player.
+func (p *AnimationPlayer) Render() {
+	if p.Sprite != nil {
+		p.Sprite.Render()
+	}
+}
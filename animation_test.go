@@ -0,0 +1,146 @@
+package banana
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testFrameDuration = 100 * time.Millisecond
+
+// testAnimation returns an animation whose frames are regions of an imaginary strip, one per name and frame.
+func testAnimation(name string, frames int, mode AnimationMode) *Animation {
+	regions := make([]Region, frames)
+	for i := range regions {
+		regions[i] = NewRegion(uint32(name[0]), i*8, 0, 8, 8)
+	}
+	a := NewAnimation(name, regions, testFrameDuration)
+	a.Mode = mode
+	return a
+}
+
+func TestAnimationPlayerAdvance(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   int
+		mode     AnimationMode
+		speed    float32
+		advances []time.Duration
+		want     []int
+	}{
+		{
+			name:     "loop wraps around",
+			frames:   3,
+			advances: []time.Duration{99 * time.Millisecond, time.Millisecond, testFrameDuration, testFrameDuration},
+			want:     []int{0, 1, 2, 0},
+		},
+		{
+			name:     "ping-pong turns around at both ends",
+			frames:   3,
+			mode:     AnimationPingPong,
+			advances: []time.Duration{testFrameDuration, testFrameDuration, testFrameDuration, testFrameDuration, testFrameDuration},
+			want:     []int{1, 2, 1, 0, 1},
+		},
+		{
+			name:     "ping-pong with one frame stays put",
+			frames:   1,
+			mode:     AnimationPingPong,
+			advances: []time.Duration{testFrameDuration, testFrameDuration},
+			want:     []int{0, 0},
+		},
+		{
+			name:     "large steps skip frames and keep the remainder",
+			frames:   4,
+			advances: []time.Duration{250 * time.Millisecond, 60 * time.Millisecond, 500 * time.Millisecond},
+			want:     []int{2, 3, 0},
+		},
+		{
+			name:     "once stops on the last frame",
+			frames:   3,
+			mode:     AnimationOnce,
+			advances: []time.Duration{time.Second, time.Second},
+			want:     []int{2, 2},
+		},
+		{
+			name:     "speed scales time",
+			frames:   3,
+			speed:    2,
+			advances: []time.Duration{50 * time.Millisecond, 50 * time.Millisecond},
+			want:     []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewAnimationPlayer(nil)
+			p.Speed = tt.speed
+			p.Add(testAnimation("clip", tt.frames, tt.mode))
+			p.Play("clip")
+
+			var got []int
+			for _, d := range tt.advances {
+				p.Advance(d)
+				got = append(got, p.Frame())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frames after each advance = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnimationPlayerFinished(t *testing.T) {
+	p := NewAnimationPlayer(nil)
+	p.Add(testAnimation("die", 2, AnimationOnce))
+	var finished []string
+	p.OnFinished = func(name string) { finished = append(finished, name) }
+	p.Play("die")
+
+	p.Advance(testFrameDuration)
+	if p.Finished() || len(finished) != 0 {
+		t.Fatalf("finished on the last frame before it was shown in full")
+	}
+	p.Advance(testFrameDuration)
+	p.Advance(time.Second)
+	if !p.Finished() || !reflect.DeepEqual(finished, []string{"die"}) {
+		t.Errorf("Finished() = %v and OnFinished saw %v, want true and [die]", p.Finished(), finished)
+	}
+
+	p.Restart("die")
+	if p.Finished() || p.Frame() != 0 {
+		t.Errorf("Restart left the player finished on frame %d", p.Frame())
+	}
+}
+
+func TestAnimationPlayerEvents(t *testing.T) {
+	walk := testAnimation("walk", 4, AnimationLoop)
+	walk.AddEvent(0, "step")
+	walk.AddEvent(2, "step")
+	walk.AddEvent(3, "jump")
+	jump := testAnimation("jump", 2, AnimationOnce)
+	jump.AddEvent(0, "takeoff")
+
+	sprite := NewSprite(Region{})
+	p := NewAnimationPlayer(sprite)
+	p.Add(walk, jump)
+	var events []string
+	p.OnEvent = func(event string) {
+		events = append(events, event)
+		if event == "jump" {
+			p.Play("jump")
+		}
+	}
+
+	p.Play("walk")
+	// passes frame 3, whose event switches animations and drops the rest of the time
+	p.Advance(time.Second)
+
+	if want := []string{"step", "step", "jump", "takeoff"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if p.Current() != jump || p.Frame() != 0 {
+		t.Errorf("playing %q frame %d, want jump frame 0", p.Current().Name, p.Frame())
+	}
+	if sprite.Region != jump.Frames[0].Region {
+		t.Error("the sprite does not show the first frame of jump")
+	}
+}
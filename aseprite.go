@@ -0,0 +1,155 @@
+package banana
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+)
+
+// Slice is a named area that Aseprite users draw on the canvas, such as a hitbox or the center of a nine-slice.
+// A slice can change over the animation; each key applies from its frame until the next key.
+type Slice struct {
+	Name string
+	Keys []SliceKey
+}
+
+// SliceKey is the shape of a slice from a frame on. Bounds are in the pixels of the untrimmed frame.
+type SliceKey struct {
+	Frame  int
+	Bounds image.Rectangle
+	// Center is the stretchable center of a nine-slice relative to Bounds, empty when the slice has none.
+	Center image.Rectangle
+	// Pivot is relative to Bounds and only valid when HasPivot is set.
+	Pivot    image.Point
+	HasPivot bool
+}
+
+// At returns the key in effect on a frame.
+func (s Slice) At(frame int) (SliceKey, bool) {
+	var key SliceKey
+	found := false
+	for _, k := range s.Keys {
+		if k.Frame <= frame && (!found || k.Frame >= key.Frame) {
+			key, found = k, true
+		}
+	}
+	return key, found
+}
+
+// Insets returns the insets of a nine-slice key for RenderNineSlice.
+func (k SliceKey) Insets() Insets {
+	return Insets{
+		Left:   float32(k.Center.Min.X),
+		Top:    float32(k.Center.Min.Y),
+		Right:  float32(k.Bounds.Dx() - k.Center.Max.X),
+		Bottom: float32(k.Bounds.Dy() - k.Center.Max.Y),
+	}
+}
+
+type asepriteTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Repeat    string `json:"repeat"`
+}
+
+type asepriteFile struct {
+	Frames jsonFrames `json:"frames"`
+	Meta   struct {
+		FrameTags []asepriteTag `json:"frameTags"`
+		Slices    []struct {
+			Name string `json:"name"`
+			Keys []struct {
+				Frame  int       `json:"frame"`
+				Bounds jsonRect  `json:"bounds"`
+				Center *jsonRect `json:"center"`
+				Pivot  *struct {
+					X int `json:"x"`
+					Y int `json:"y"`
+				} `json:"pivot"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+// LoadAseprite loads a sheet exported by Aseprite with its JSON data, in either the hash or the array format.
+// The image is read from the path the JSON file names relative to itself.
+func LoadAseprite(path string) (*SpriteSheet, error) {
+	return loadSheetFile(path, ParseAseprite)
+}
+
+// ParseAseprite creates a sprite sheet from the JSON data Aseprite exported with img.
+// Every tag becomes an animation named after the tag, playing in the tag's direction.
+// Tags that repeat once play with AnimationOnce; other repeat counts loop forever.
+func ParseAseprite(data []byte, img image.Image) (*SpriteSheet, error) {
+	var file asepriteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	return newSpriteSheet(img, file.Frames, func(sheet *SpriteSheet) error {
+		for _, tag := range file.Meta.FrameTags {
+			if err := addAsepriteTag(sheet, tag); err != nil {
+				return err
+			}
+		}
+		for _, s := range file.Meta.Slices {
+			slice := Slice{Name: s.Name}
+			for _, k := range s.Keys {
+				key := SliceKey{Frame: k.Frame, Bounds: k.Bounds.rectangle()}
+				if k.Center != nil {
+					key.Center = k.Center.rectangle()
+				}
+				if k.Pivot != nil {
+					key.Pivot = image.Pt(k.Pivot.X, k.Pivot.Y)
+					key.HasPivot = true
+				}
+				slice.Keys = append(slice.Keys, key)
+			}
+			sheet.Slices[s.Name] = slice
+		}
+		return nil
+	})
+}
+
+// addAsepriteTag adds the animation of a frame tag.
+func addAsepriteTag(sheet *SpriteSheet, tag asepriteTag) error {
+	if tag.From > tag.To {
+		return fmt.Errorf("tag %q ends before it starts", tag.Name)
+	}
+	var indices []int
+	for i := tag.From; i <= tag.To; i++ {
+		indices = append(indices, i)
+	}
+
+	mode := AnimationLoop
+	switch tag.Direction {
+	case "", "forward":
+	case "reverse":
+		reverse(indices)
+	case "pingpong":
+		mode = AnimationPingPong
+	case "pingpong_reverse":
+		reverse(indices)
+		mode = AnimationPingPong
+	default:
+		return fmt.Errorf("tag %q has unknown direction %q", tag.Name, tag.Direction)
+	}
+	if tag.Repeat == "1" && mode == AnimationLoop {
+		mode = AnimationOnce
+	}
+
+	a, err := sheet.animation(tag.Name, indices, mode)
+	if err != nil {
+		return err
+	}
+	sheet.Animations[tag.Name] = a
+	return nil
+}
+
+func reverse(indices []int) {
+	for i, j := 0, len(indices)-1; i < j; i, j = i+1, j-1 {
+		indices[i], indices[j] = indices[j], indices[i]
+	}
+}
@@ -11,6 +11,9 @@ var DefaultTextureImage []byte
 //go:embed  images/buddy_dance.png
 var BuddyDanceSpriteSheet []byte
 
+//go:embed images/buddy_dance.json
+var BuddyDanceSpriteSheetData []byte
+
 //go:embed fonts/ComicShannsMono/ComicShannsMonoNerdFont-Regular.otf
 var ComicShannsMonoNerdFontRegular []byte
 
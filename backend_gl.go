@@ -0,0 +1,27 @@
+//go:build !nogl && !tinygo
+
+package banana
+
+import (
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/opengl"
+	"github.com/dfirebaugh/banana/graphics/window"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// newDefaultBackend opens the OpenGL window that is used unless SetBackend was called.
+func newDefaultBackend(width, height int) (graphics.GraphicsBackend, error) {
+	gb, err := opengl.NewGraphicsBackend(width, height)
+	if err != nil {
+		return nil, err
+	}
+	return gb, nil
+}
+
+func setDebugContext(enabled bool) {
+	window.DebugContext = enabled
+}
+
+func viewport(x, y, width, height int32) {
+	gl.Viewport(x, y, width, height)
+}
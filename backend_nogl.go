@@ -0,0 +1,17 @@
+//go:build nogl || tinygo
+
+package banana
+
+import (
+	"errors"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+func newDefaultBackend(width, height int) (graphics.GraphicsBackend, error) {
+	return nil, errors.New("banana: built without OpenGL, call SetBackend before anything else")
+}
+
+func setDebugContext(enabled bool) {}
+
+func viewport(x, y, width, height int32) {}
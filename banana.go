@@ -2,14 +2,15 @@ package banana
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/dfirebaugh/banana/graphics"
-	"github.com/dfirebaugh/banana/graphics/opengl"
+	"github.com/dfirebaugh/banana/pkg/fb"
 	"github.com/dfirebaugh/banana/pkg/input"
-	"github.com/go-gl/gl/v4.6-core/gl"
 )
 
 type Game interface {
@@ -22,12 +23,25 @@ var (
 	windowHeight = 160
 )
 
+// TickDuration is the game time that passes between two calls of the update function.
+const TickDuration = time.Second / 120
+
 type engine struct {
 	graphicsBackend   graphics.GraphicsBackend
 	inputState        *input.InputState
 	windowTitle       string
 	fpsCounter        *fpsCounter
 	hasSetupCompleted bool
+	imageFBTextures   map[*fb.ImageFB]*streamedTexture
+	indexedFBTextures map[*fb.IndexedFB]*streamedTexture
+
+	// frameHooks can be added and removed from other goroutines while frames are drawn.
+	frameHooksMu sync.Mutex
+	frameHooks   []*frameHook
+
+	// injected holds events from other goroutines until the next frame.
+	injectedMu sync.Mutex
+	injected   []input.Event
 }
 
 var banana = &engine{}
@@ -35,8 +49,12 @@ var banana = &engine{}
 func setup() {
 	runtime.LockOSThread()
 	banana.inputState = input.NewInputState()
-	var err error
-	gb, err := opengl.NewGraphicsBackend(windowWidth, windowHeight)
+	if banana.graphicsBackend != nil {
+		windowWidth, windowHeight = banana.graphicsBackend.GetWindowSize()
+		banana.hasSetupCompleted = true
+		return
+	}
+	gb, err := newDefaultBackend(windowWidth, windowHeight)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -44,6 +62,16 @@ func setup() {
 	banana.hasSetupCompleted = true
 }
 
+// SetBackend replaces the OpenGL window with another graphics backend,
+// such as software.New drawing into an fb.Displayer. It must be called before anything else.
+// Building with the nogl tag, or with TinyGo, leaves OpenGL out and then SetBackend is required.
+func SetBackend(backend graphics.GraphicsBackend) {
+	if banana.hasSetupCompleted {
+		panic("banana: SetBackend must be called before the engine is set up")
+	}
+	banana.graphicsBackend = backend
+}
+
 func initWindow() {
 	SetWindowSize(windowWidth, windowHeight)
 	SetTitle("banana")
@@ -69,25 +97,23 @@ func run(updateFn func(), renderFn func()) {
 		evt := <-eventChan
 		handleEvent(evt, banana.inputState)
 	})
-	targetFPS := 120.0
-	targetFrameDuration := time.Second / time.Duration(targetFPS)
-
 	var lastUpdateTime time.Time
 	var accumulator time.Duration
 
 	lastUpdateTime = time.Now()
 	for banana.graphicsBackend.PollEvents() {
+		handleInjectedEvents()
 		currentTime := time.Now()
 		deltaTime := currentTime.Sub(lastUpdateTime)
 		lastUpdateTime = currentTime
 		accumulator += deltaTime
 
 		frameRendered := false
-		for accumulator >= targetFrameDuration {
+		for accumulator >= TickDuration {
 			if updateFn != nil {
 				updateFn()
 			}
-			accumulator -= targetFrameDuration
+			accumulator -= TickDuration
 			frameRendered = true
 
 		}
@@ -99,6 +125,7 @@ func run(updateFn func(), renderFn func()) {
 
 			calculateFPS()
 			banana.graphicsBackend.Draw()
+			runFrameHooks()
 			banana.graphicsBackend.SwapBuffers()
 			banana.inputState.ResetJustPressed()
 		}
@@ -131,6 +158,46 @@ func calculateFPS() {
 	banana.graphicsBackend.SetWindowTitle(title)
 }
 
+type frameHook struct {
+	fn func()
+}
+
+// OnFrameDrawn registers fn to be called after every frame is drawn and before it is shown.
+// ReadScreen can be called from fn. Calling remove unregisters fn; it is safe to call from any goroutine.
+func OnFrameDrawn(fn func()) (remove func()) {
+	hook := &frameHook{fn: fn}
+	banana.frameHooksMu.Lock()
+	banana.frameHooks = append(banana.frameHooks, hook)
+	banana.frameHooksMu.Unlock()
+
+	return func() {
+		banana.frameHooksMu.Lock()
+		defer banana.frameHooksMu.Unlock()
+		for i, h := range banana.frameHooks {
+			if h == hook {
+				banana.frameHooks = append(banana.frameHooks[:i:i], banana.frameHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func runFrameHooks() {
+	banana.frameHooksMu.Lock()
+	hooks := banana.frameHooks
+	banana.frameHooksMu.Unlock()
+
+	for _, hook := range hooks {
+		hook.fn()
+	}
+}
+
+// ReadScreen returns a copy of the frame that was just drawn. It is only valid from an OnFrameDrawn hook.
+func ReadScreen() *image.RGBA {
+	ensureSetupCompletion()
+	return banana.graphicsBackend.ReadScreen()
+}
+
 func GetFPS() float64 {
 	return banana.fpsCounter.GetFPS()
 }
@@ -224,19 +291,22 @@ func SetResizeCallback(fn func(physicalWidth, physicalHeight uint32)) {
 	banana.graphicsBackend.SetResizedCallback(fn)
 }
 
+// BindFramebuffer sends everything rendered until UnbindFramebuffer into fb.
+// Framebuffers can be bound at any point of the render callback; their contents are drawn
+// before anything that samples them when the frame is rendered.
 func BindFramebuffer(fb graphics.Framebuffer) {
 	ensureSetupCompletion()
 	banana.graphicsBackend.BindFramebuffer(fb)
 }
 
+// UnbindFramebuffer returns to the target that was bound before the framebuffer,
+// restoring its viewport.
 func UnbindFramebuffer() {
 	ensureSetupCompletion()
 	banana.graphicsBackend.UnbindFramebuffer()
-	windowWidth, windowHeight := GetWindowSize()
-	Viewport(0, 0, int32(windowWidth), int32(windowHeight))
 }
 
 func Viewport(x int32, y int32, width int32, height int32) {
 	ensureSetupCompletion()
-	gl.Viewport(x, y, width, height)
+	viewport(x, y, width, height)
 }
@@ -9,6 +9,9 @@ import (
 type Circle struct {
 	X, Y, Radius float32
 	Color        color.Color
+	// StrokeWidth draws an outline of StrokeColor along the inside of the edge.
+	StrokeWidth float32
+	StrokeColor color.Color
 }
 
 func (c *Circle) GetVertices(screenWidth, screenHeight int) []graphics.Vertex {
@@ -33,15 +36,17 @@ func (c *Circle) GetVertices(screenWidth, screenHeight int) []graphics.Vertex {
 	var result []graphics.Vertex
 	for i := 0; i < 6; i++ {
 		v := graphics.Vertex{
-			FsQuadPos:  [2]float32{vertices[i*2], vertices[i*2+1]},
-			ShapePos:   [2]float32{normX, normY},
-			LocalPos:   [2]float32{vertices[i*2], vertices[i*2+1]},
-			OpCode:     graphics.OP_CODE_CIRCLE,
-			Radius:     c.Radius,
-			Width:      c.Radius * 2.0,
-			Height:     c.Radius * 2.0,
-			Color:      color,
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
+			FsQuadPos:   [2]float32{vertices[i*2], vertices[i*2+1]},
+			ShapePos:    [2]float32{normX, normY},
+			LocalPos:    [2]float32{vertices[i*2], vertices[i*2+1]},
+			OpCode:      graphics.OP_CODE_CIRCLE,
+			Radius:      c.Radius,
+			Width:       c.Radius * 2.0,
+			Height:      c.Radius * 2.0,
+			Color:       color,
+			Resolution:  [2]float32{float32(screenWidth), float32(screenHeight)},
+			StrokeWidth: c.StrokeWidth,
+			StrokeColor: colorToVec4(c.StrokeColor),
 		}
 		result = append(result, v)
 	}
@@ -0,0 +1,100 @@
+package banana
+
+import (
+	"image/color"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+// ShapeType identifies a shape registered with RegisterShape.
+type ShapeType graphics.OpCode
+
+// RegisterShape adds a shape drawn from a signed distance function to the renderer.
+//
+// sdf is the body of a GLSL function with the signature
+//
+//	float sd(vec2 p, vec2 size, float radius, vec4 params)
+//
+// It returns the distance in pixels from p to the edge of the shape, negative inside.
+// p is measured from the center of the shape with y pointing down, size is the shape's
+// width and height, and radius and params are passed through from the CustomShape.
+// For example, a diamond:
+//
+//	vec2 q = abs(p) / (size * 0.5);
+//	return (q.x + q.y - 1.0) * min(size.x, size.y) * 0.35;
+//
+// Custom shapes are filled, anti-aliased and stroked like Rect and Circle.
+// A GLSL error is returned with the compiler's log and leaves the renderer unchanged.
+func RegisterShape(name string, sdf string) (ShapeType, error) {
+	ensureSetupCompletion()
+	opCode, err := banana.graphicsBackend.RegisterShape(name, sdf)
+	return ShapeType(opCode), err
+}
+
+// CustomShape draws a registered shape in the rectangle at X, Y with the given size.
+type CustomShape struct {
+	Type                ShapeType
+	X, Y, Width, Height float32
+	Radius              float32
+	// Params are passed to the shape's distance function.
+	Params      [4]float32
+	Color       color.Color
+	StrokeWidth float32
+	StrokeColor color.Color
+}
+
+func (s *CustomShape) GetVertices(screenWidth, screenHeight int) []graphics.Vertex {
+	return sdfQuad(s.X, s.Y, s.Width, s.Height, graphics.Vertex{
+		OpCode:      graphics.OpCode(s.Type),
+		Radius:      s.Radius,
+		Color:       colorToVec4(s.Color),
+		TexRect:     s.Params,
+		StrokeWidth: s.StrokeWidth,
+		StrokeColor: colorToVec4(s.StrokeColor),
+	}, screenWidth, screenHeight)
+}
+
+// sdfQuad builds the quad a signed distance shape is evaluated on.
+// x and y are the top-left corner and every other attribute is copied from the template.
+func sdfQuad(x, y, width, height float32, template graphics.Vertex, screenWidth, screenHeight int) []graphics.Vertex {
+	normX, normY := normalizeCoordinates(x, y, screenWidth, screenHeight)
+	halfWidth := width * 0.5
+	halfHeight := height * 0.5
+
+	corners := [6][2]float32{
+		{-halfWidth, halfHeight},
+		{-halfWidth, -halfHeight},
+		{halfWidth, -halfHeight},
+		{-halfWidth, halfHeight},
+		{halfWidth, -halfHeight},
+		{halfWidth, halfHeight},
+	}
+
+	template.ShapePos = [2]float32{normX + halfWidth/float32(screenWidth)*2.0, normY - halfHeight/float32(screenHeight)*2.0}
+	template.Width = width
+	template.Height = height
+	template.Resolution = [2]float32{float32(screenWidth), float32(screenHeight)}
+
+	vertices := make([]graphics.Vertex, len(corners))
+	for i, corner := range corners {
+		v := template
+		v.FsQuadPos = corner
+		v.LocalPos = corner
+		vertices[i] = v
+	}
+	return vertices
+}
+
+// colorToVec4 converts a color to normalized RGBA. A nil color is transparent.
+func colorToVec4(c color.Color) [4]float32 {
+	if c == nil {
+		return [4]float32{}
+	}
+	r, g, b, a := c.RGBA()
+	return [4]float32{
+		float32(r) / 65535.0,
+		float32(g) / 65535.0,
+		float32(b) / 65535.0,
+		float32(a) / 65535.0,
+	}
+}
@@ -0,0 +1,43 @@
+package banana
+
+import (
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// Logger receives the engine's diagnostics. *logrus.Logger satisfies it.
+type Logger diag.Logger
+
+// LogLevel is the severity of a diagnostic message.
+type LogLevel diag.Level
+
+const (
+	LogLevelDebug = LogLevel(diag.LevelDebug)
+	LogLevelInfo  = LogLevel(diag.LevelInfo)
+	LogLevelWarn  = LogLevel(diag.LevelWarn)
+	LogLevelError = LogLevel(diag.LevelError)
+	LogLevelOff   = LogLevel(diag.LevelOff)
+)
+
+// SetLogger sends the engine's diagnostics, including GL debug output, to l.
+// A nil logger restores the default one, which writes to stderr.
+// It can be called before the window is created.
+func SetLogger(l Logger) {
+	diag.SetLogger(l)
+}
+
+// SetLogLevel drops diagnostics below a severity. The default is LogLevelInfo.
+// It can be called before the window is created.
+func SetLogLevel(level LogLevel) {
+	diag.SetLevel(diag.Level(level))
+}
+
+// SetGLDebug turns the driver's debug output on or off. GL errors, undefined behavior
+// and performance warnings are then logged as they happen.
+//
+// Call it before anything else to get a debug context, on which drivers report everything.
+// Called later it still works, but some drivers only report to debug contexts.
+func SetGLDebug(enabled bool) {
+	setDebugContext(enabled)
+	ensureSetupCompletion()
+	banana.graphicsBackend.SetDebugOutput(enabled)
+}
@@ -17,3 +17,23 @@ func handleEvent(evt input.Event, state *input.InputState) {
 		state.CursorPosition.Y = evt.Y
 	}
 }
+
+// InjectEvent feeds an input event to the engine as if it came from the window, for input that
+// arrives from elsewhere such as a remote client. It is safe to call from any goroutine;
+// the event is applied before the next update.
+func InjectEvent(evt input.Event) {
+	banana.injectedMu.Lock()
+	banana.injected = append(banana.injected, evt)
+	banana.injectedMu.Unlock()
+}
+
+func handleInjectedEvents() {
+	banana.injectedMu.Lock()
+	events := banana.injected
+	banana.injected = nil
+	banana.injectedMu.Unlock()
+
+	for _, evt := range events {
+		handleEvent(evt, banana.inputState)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/dfirebaugh/banana"
+	"golang.org/x/image/colornames"
+)
+
+// Plays an animated GIF, e.g. `go run ./examples/gif spinner.gif`.
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: gif <file.gif>")
+	}
+
+	banana.SetWindowSize(320, 240)
+	banana.SetTitle("banana.gif example")
+
+	animation, err := banana.LoadGIF(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sprite := banana.NewSprite(animation.Frames[0].Region)
+	sprite.PivotX, sprite.PivotY = 0.5, 0.5
+	player := banana.NewAnimationPlayer(sprite)
+	player.Add(animation)
+	player.Play(animation.Name)
+
+	banana.Run(func() {
+		player.Update()
+	}, func() {
+		banana.Clear(colornames.Skyblue)
+		width, height := banana.GetWindowSize()
+		sprite.X, sprite.Y = float32(width)/2, float32(height)/2
+		player.Render()
+	})
+}
@@ -79,11 +79,7 @@ func renderGrid(fb *fb.ImageFB) {
 			if grid[x][y] {
 				cellColor = colornames.White
 			}
-			for i := 0; i < cellSize; i++ {
-				for j := 0; j < cellSize; j++ {
-					fb.SetPixel(int16(x*cellSize+i), int16(y*cellSize+j), cellColor)
-				}
-			}
+			fb.FillRect(x*cellSize, y*cellSize, cellSize, cellSize, cellColor)
 		}
 	}
 }
@@ -105,28 +101,16 @@ func main() {
 		}
 	}
 	renderGrid(framebuffer)
-	texture := banana.UploadTexture(framebuffer.ToImage())
 
 	banana.Run(func() {
 		exampleControls()
 		if banana.IsKeyPressed(input.KeyR) {
 			initGrid()
-			renderGrid(framebuffer)
-			banana.UpdateTexture(texture, framebuffer.ToImage(), 0, 0)
 		}
 		updateGrid()
 		renderGrid(framebuffer)
-		banana.UpdateTexture(texture, framebuffer.ToImage(), 0, 0)
 	}, func() {
 		banana.Clear(colornames.Black)
-		banana.RenderTexture(texture, &banana.TextureRenderOptions{
-			X:          0,
-			Y:          0,
-			RectWidth:  gridWidth,
-			RectHeight: gridHeight,
-			Width:      gridWidth,
-			Height:     gridHeight,
-			Scale:      1.0,
-		})
+		banana.RenderImageFB(framebuffer, nil)
 	})
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/pkg/fb"
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+const (
+	width  = 240
+	height = 160
+	// the rings are drawn with indices firstRing to lastRing, which are cycled every frame
+	firstRing = 1
+	lastRing  = 64
+	textIndex = 255
+)
+
+// rainbow fills the ring entries with hues around the color wheel, shifted by offset.
+func rainbow(offset float64) color.Palette {
+	palette := make(color.Palette, fb.PaletteSize)
+	palette[0] = color.RGBA{0, 0, 0, 255}
+	for i := firstRing; i <= lastRing; i++ {
+		t := float64(i-firstRing)/float64(lastRing-firstRing+1)*2*math.Pi + offset
+		palette[i] = color.RGBA{
+			R: uint8(127 + 127*math.Sin(t)),
+			G: uint8(127 + 127*math.Sin(t+2*math.Pi/3)),
+			B: uint8(127 + 127*math.Sin(t+4*math.Pi/3)),
+			A: 255,
+		}
+	}
+	palette[textIndex] = color.RGBA{255, 255, 255, 255}
+	return palette
+}
+
+func main() {
+	banana.SetWindowSize(width*4, height*4)
+
+	palettes := []color.Palette{rainbow(0), rainbow(math.Pi)}
+	for i := firstRing; i <= lastRing; i++ {
+		// a gray ramp to swap to
+		v := uint8(i * 255 / lastRing)
+		palettes[1][i] = color.RGBA{v, v, v, 255}
+	}
+	current := 0
+
+	screen := fb.NewIndexed(width, height, palettes[current])
+	for r := width; r > 0; r-- {
+		screen.FillCircle(width/2, height/2, r, uint8(firstRing+r%(lastRing-firstRing+1)))
+	}
+	screen.DrawText(nil, "space swaps the palette", 4, 4, 1, textIndex)
+
+	banana.Run(func() {
+		if banana.IsKeyJustPressed(input.KeyEscape) {
+			banana.Close()
+		}
+		if banana.IsKeyJustPressed(input.KeySpace) {
+			current = (current + 1) % len(palettes)
+			screen.SetPalette(palettes[current])
+		}
+		// only the palette changes, the pixels are uploaded once
+		screen.CyclePalette(firstRing, lastRing, 1)
+	}, func() {
+		banana.RenderIndexedFB(screen, &banana.TextureRenderOptions{Scale: 4})
+	})
+}
@@ -31,11 +31,8 @@ type Player struct {
 	H              float32
 	VelY           float32
 	Ground         bool
-	TextureID      uint32
-	FrameSize      image.Point
-	SheetSize      image.Point
-	FrameIndex     int
-	LastFrame      time.Time
+	Sprite         *banana.Sprite
+	Animations     *banana.AnimationPlayer
 	LastUpdateTime time.Time
 	platforms      []*Platform
 
@@ -44,11 +41,21 @@ type Player struct {
 }
 
 func (p *Player) handleMovement(deltaTime float32) {
+	moving := false
 	if banana.IsKeyPressed(input.KeyA) || banana.IsKeyPressed(input.KeyLeft) {
 		p.X -= playerSpeed * deltaTime
+		p.Sprite.FlipX = true
+		moving = true
 	}
 	if banana.IsKeyPressed(input.KeyD) || banana.IsKeyPressed(input.KeyRight) {
 		p.X += playerSpeed * deltaTime
+		p.Sprite.FlipX = false
+		moving = true
+	}
+	if moving {
+		p.Animations.Play("run")
+	} else {
+		p.Animations.Play("idle")
 	}
 }
 
@@ -102,13 +109,6 @@ func (p *Player) updateVelocity(deltaTime float32) {
 	p.Y += p.VelY * deltaTime
 }
 
-func (p *Player) updateSpriteFrame() {
-	if time.Since(p.LastFrame) >= time.Millisecond*200 {
-		p.LastFrame = time.Now()
-		p.FrameIndex = (p.FrameIndex + 1) % (p.SheetSize.X * p.SheetSize.Y)
-	}
-}
-
 func (p *Player) handleJump() {
 	if banana.IsKeyPressed(input.KeySpace) && (p.Ground || p.CoyoteTimeLeft > 0) {
 		p.VelY = -jumpSpeed
@@ -124,7 +124,7 @@ func (p *Player) Update(deltaTime float32) {
 	p.handleCoyoteTime(deltaTime)
 
 	p.handleJump()
-	p.updateSpriteFrame()
+	p.Animations.Update()
 
 	p.handlePlatformCollision()
 	p.Rect.X = p.X
@@ -132,22 +132,11 @@ func (p *Player) Update(deltaTime float32) {
 }
 
 func (p *Player) Render() {
-	frameX := (p.FrameIndex % p.SheetSize.X) * p.FrameSize.X
-	frameY := (p.FrameIndex / p.SheetSize.X) * p.FrameSize.Y
-
-	options := &banana.TextureRenderOptions{
-		X:          p.X,
-		Y:          p.Y,
-		RectWidth:  float32(p.FrameSize.X),
-		RectHeight: float32(p.FrameSize.Y),
-		Scale:      float32(p.W) / float32(p.FrameSize.X),
-		RectX:      float32(frameX),
-		RectY:      float32(frameY),
-		Width:      float32(p.FrameSize.X),
-		Height:     float32(p.FrameSize.Y),
-	}
-
-	banana.RenderTexture(p.TextureID, options)
+	p.Sprite.X = p.X
+	p.Sprite.Y = p.Y
+	p.Sprite.ScaleX = p.W / float32(p.Sprite.Region.Width())
+	p.Sprite.ScaleY = p.H / float32(p.Sprite.Region.Height())
+	p.Sprite.Render()
 
 	if debug {
 		banana.RenderShape(p.Rect)
@@ -166,8 +155,15 @@ func main() {
 		panic(err)
 	}
 	textureID := banana.UploadTexture(img)
-	frameSize := image.Point{X: 32, Y: 32}
-	sheetSize := image.Point{X: 4, Y: 1}
+	bounds := img.Bounds()
+	frames := banana.NewRegion(textureID, 0, 0, bounds.Dx(), bounds.Dy()).Split(32, 32)
+
+	sprite := banana.NewSprite(frames[0])
+	animations := banana.NewAnimationPlayer(sprite)
+	idle := banana.NewAnimation("idle", frames, 200*time.Millisecond)
+	idle.Mode = banana.AnimationPingPong
+	animations.Add(idle, banana.NewAnimation("run", frames, 100*time.Millisecond))
+	animations.Play("idle")
 
 	platforms := []*Platform{
 		NewPlatform(200, 400, 100, 20),
@@ -179,10 +175,8 @@ func main() {
 		Y:              float32(windowHeight) - 100,
 		W:              64,
 		H:              64,
-		TextureID:      textureID,
-		FrameSize:      frameSize,
-		SheetSize:      sheetSize,
-		LastFrame:      time.Now(),
+		Sprite:         sprite,
+		Animations:     animations,
 		LastUpdateTime: time.Now(),
 		platforms:      platforms,
 		Rect: &banana.Rect{
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+
+	"golang.org/x/image/colornames"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+const wave = `#version 330 core
+in vec2 v_uv;
+out vec4 frag_color;
+uniform sampler2D u_texture;
+uniform float u_time;
+uniform float u_strength;
+
+void main() {
+    vec2 uv = v_uv;
+    uv.x += sin(uv.y * 30.0 + u_time * 4.0) * u_strength;
+    frag_color = texture(u_texture, uv);
+}
+`
+
+const vignette = `#version 330 core
+in vec2 v_uv;
+out vec4 frag_color;
+uniform sampler2D u_texture;
+
+void main() {
+    vec4 c = texture(u_texture, v_uv);
+    float d = distance(v_uv, vec2(0.5));
+    c.rgb *= smoothstep(0.8, 0.3, d);
+    frag_color = c;
+}
+`
+
+func main() {
+	banana.SetWindowSize(640, 480)
+	banana.SetTitle("banana.postprocess example")
+
+	waveShader, err := banana.NewShader("", wave)
+	if err != nil {
+		panic(err)
+	}
+	vignetteShader, err := banana.NewShader("", vignette)
+	if err != nil {
+		panic(err)
+	}
+	waveShader.SetFloat("u_strength", 0.005)
+
+	enabled := true
+	banana.SetPostProcess(waveShader, vignetteShader)
+
+	var t float64
+	banana.Run(func() {
+		t += 1.0 / 120.0
+		if banana.IsKeyJustPressed(input.KeySpace) {
+			enabled = !enabled
+			if enabled {
+				banana.SetPostProcess(waveShader, vignetteShader)
+			} else {
+				banana.SetPostProcess()
+			}
+		}
+		if banana.IsKeyJustPressed(input.KeyEscape) {
+			banana.Close()
+		}
+	}, func() {
+		banana.Clear(colornames.Skyblue)
+		for i := 0; i < 8; i++ {
+			banana.RenderShape(&banana.Circle{
+				X:      float32(80 + i*70),
+				Y:      float32(240 + 100*math.Sin(t*2+float64(i))),
+				Radius: 24,
+				Color:  colornames.Tomato,
+			})
+		}
+		banana.RenderText("space toggles post processing", &banana.TextRenderOptions{
+			X:     20,
+			Y:     30,
+			Size:  16,
+			Color: colornames.White,
+		})
+	})
+}
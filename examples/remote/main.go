@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/graphics/software"
+	"github.com/dfirebaugh/banana/pkg/fb"
+	"github.com/dfirebaugh/banana/pkg/input"
+	"github.com/dfirebaugh/banana/remote"
+	"golang.org/x/image/colornames"
+)
+
+// Serves the game over VNC. Connect with any VNC client, e.g. `vncviewer 127.0.0.1:5900`,
+// move the ball with the arrow keys and click to place it.
+// With -headless the game runs without a window.
+func main() {
+	addr := flag.String("addr", "127.0.0.1:5900", "address to serve VNC on")
+	headless := flag.Bool("headless", false, "render in software without a window")
+	flag.Parse()
+
+	if *headless {
+		banana.SetBackend(software.New(fb.New(240, 160)))
+	}
+
+	server, err := remote.Serve(*addr, remote.Options{Name: "banana remote example"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer server.Close()
+
+	x, y := 120, 80
+	banana.Run(func() {
+		if banana.IsKeyPressed(input.KeyLeft) {
+			x--
+		}
+		if banana.IsKeyPressed(input.KeyRight) {
+			x++
+		}
+		if banana.IsKeyPressed(input.KeyUp) {
+			y--
+		}
+		if banana.IsKeyPressed(input.KeyDown) {
+			y++
+		}
+		if banana.IsButtonPressed(input.MouseButtonLeft) {
+			x, y = banana.GetCursorPosition()
+		}
+	}, func() {
+		banana.Clear(colornames.Skyblue)
+		banana.RenderShape(&banana.Circle{
+			X:      float32(x),
+			Y:      float32(y),
+			Radius: 10,
+			Color:  colornames.Tomato,
+		})
+	})
+}
@@ -28,6 +28,19 @@ func main() {
 		}
 	}
 
+	star, err := banana.RegisterShape("star", `
+		// five pointed star, params.x is the depth of the points
+		const float pi = 3.14159265;
+		float r = min(size.x, size.y) * 0.5;
+		float a = atan(p.x, -p.y);
+		float segment = 2.0 * pi / 5.0;
+		float f = abs(mod(a, segment) - segment * 0.5) / (segment * 0.5);
+		return length(p) - r * mix(params.x, 1.0, f * f);
+	`)
+	if err != nil {
+		panic(err)
+	}
+
 	banana.Run(func() {
 		setFullScreen()
 	}, func() {
@@ -88,6 +101,18 @@ func main() {
 			},
 		})
 
+		banana.RenderShape(&banana.CustomShape{
+			Type:        star,
+			X:           200,
+			Y:           100,
+			Width:       32,
+			Height:      32,
+			Params:      [4]float32{0.45},
+			Color:       colornames.Gold,
+			StrokeWidth: 2,
+			StrokeColor: colornames.Black,
+		})
+
 		points := []gui.Position{
 			{X: 20, Y: 150},
 			{X: 60, Y: 130},
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/graphics/software"
+	"github.com/dfirebaugh/banana/pkg/fb"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	width  = 240
+	height = 160
+	frames = 60
+)
+
+// Renders into an in-memory framebuffer without a window and saves the last frame to software.png.
+// The same game code could draw to an SPI display by passing its driver to software.New.
+// Building with `-tags nogl` leaves OpenGL out, as is needed for TinyGo.
+func main() {
+	screen := fb.New(width, height)
+	backend := software.New(screen)
+	banana.SetBackend(backend)
+
+	frame := 0
+	x := float32(0)
+	banana.Run(func() {
+		x += 2
+		frame++
+		if frame == frames {
+			banana.Close()
+		}
+	}, func() {
+		banana.Clear(colornames.Skyblue)
+		banana.RenderShape(&banana.Rect{
+			X:      20,
+			Y:      100,
+			Width:  200,
+			Height: 40,
+			Radius: 8,
+			Color:  colornames.Seagreen,
+		})
+		banana.RenderShape(&banana.Circle{
+			X:      x,
+			Y:      60,
+			Radius: 16,
+			Color:  colornames.Tomato,
+		})
+	})
+	// only the tiles around the moving circle are sent once the first frame is on the display
+	log.Printf("the last frame sent %v", backend.DirtyRects())
+
+	f, err := os.Create("software.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, screen.ToImage()); err != nil {
+		log.Fatal(err)
+	}
+}
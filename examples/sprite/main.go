@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"image"
 	"image/color"
-	"time"
 
 	_ "image/png"
 
@@ -29,23 +28,20 @@ func main() {
 		panic(err)
 	}
 
-	textureID := banana.UploadTexture(img)
-
-	frameSize := image.Point{X: 32, Y: 32}
-	sheetSize := image.Point{X: 4, Y: 1}
+	sheet, err := banana.ParseAseprite(assets.BuddyDanceSpriteSheetData, img)
+	if err != nil {
+		panic(err)
+	}
+	sprite := banana.NewSprite(sheet.Frames[0].Region)
+	player := sheet.NewAnimationPlayer(sprite)
+	player.Play("dance")
 
-	frameIndex := 0
-	lastFrameTime := time.Now()
-	frameDuration := time.Millisecond * 200
 	isBorderless := true
 	isTextVisible := true
 	banana.SetBorderlessWindowed(isBorderless)
 
 	banana.Run(func() {
-		if time.Since(lastFrameTime) >= frameDuration {
-			lastFrameTime = time.Now()
-			frameIndex = (frameIndex + 1) % (sheetSize.X * sheetSize.Y)
-		}
+		player.Update()
 
 		if banana.IsKeyJustPressed(input.KeyEscape) {
 			banana.Close()
@@ -60,25 +56,10 @@ func main() {
 	}, func() {
 		banana.Clear(color.RGBA{0, 0, 0, 0})
 
-		frameX := (frameIndex % sheetSize.X) * frameSize.X
-		frameY := (frameIndex / sheetSize.X) * frameSize.Y
 		windowWidth, windowHeight := banana.GetWindowSize()
-
-		options := &banana.TextureRenderOptions{
-			X:             float32(screenWidth/2 - 256),
-			Y:             float32(screenHeight/2 - 256),
-			RectWidth:     float32(frameSize.X),
-			RectHeight:    float32(frameSize.Y),
-			Scale:         16, // 512 / 32 = 16
-			RectX:         float32(frameX),
-			RectY:         float32(frameY),
-			Width:         float32(frameSize.X),
-			Height:        float32(frameSize.Y),
-			DesiredWidth:  float32(windowWidth),
-			DesiredHeight: float32(windowHeight),
-		}
-
-		banana.RenderTexture(textureID, options)
+		sprite.ScaleX = float32(windowWidth) / float32(sprite.Region.Width())
+		sprite.ScaleY = float32(windowHeight) / float32(sprite.Region.Height())
+		sprite.Render()
 
 		if isTextVisible {
 
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/graphics/terminal"
+	"github.com/dfirebaugh/banana/pkg/input"
+	"golang.org/x/image/colornames"
+)
+
+// Draws into the terminal it is run from. Move the square with the arrow keys, quit with escape or ctrl+c.
+// Build with `-tags nogl` on machines without OpenGL or X11 headers, e.g. `CGO_ENABLED=0 go run -tags nogl ./examples/terminal`.
+func main() {
+	backend, err := terminal.New(0, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	banana.SetBackend(backend)
+
+	width, height := banana.GetWindowSize()
+	x, y := float32(width/2), float32(height/2)
+	const speed = 1
+
+	banana.Run(func() {
+		if banana.IsKeyJustPressed(input.KeyEscape) {
+			banana.Close()
+		}
+		if banana.IsKeyPressed(input.KeyLeft) {
+			x -= speed
+		}
+		if banana.IsKeyPressed(input.KeyRight) {
+			x += speed
+		}
+		if banana.IsKeyPressed(input.KeyUp) {
+			y -= speed
+		}
+		if banana.IsKeyPressed(input.KeyDown) {
+			y += speed
+		}
+	}, func() {
+		banana.Clear(colornames.Midnightblue)
+		banana.RenderShape(&banana.Rect{
+			X:      x - 8,
+			Y:      y - 8,
+			Width:  16,
+			Height: 16,
+			Radius: 4,
+			Color:  colornames.Gold,
+		})
+		cx, cy := banana.GetCursorPosition()
+		banana.RenderShape(&banana.Circle{
+			X:      float32(cx),
+			Y:      float32(cy),
+			Radius: 3,
+			Color:  colornames.Tomato,
+		})
+	})
+}
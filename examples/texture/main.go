@@ -2,6 +2,7 @@ package main
 
 import (
 	"image"
+	"math"
 	"net/http"
 
 	"golang.org/x/image/colornames"
@@ -32,8 +33,9 @@ func main() {
 	banana.SetTitle("banana.texture example")
 
 	tImage := downloadImage(`https://parade.com/.image/c_limit%2Ccs_srgb%2Cq_auto:good%2Cw_620/MTkwNTgxNDg5MjU4ODY1Nzg5/nick-offerman-donkey-thoughts.webp`)
-	t := banana.UploadTexture(
+	t := banana.UploadTextureWithOptions(
 		tImage,
+		banana.TextureOptions{Filter: banana.FilterLinear, Mipmaps: true},
 	)
 	mountainImage := downloadImage(`https://www.gstatic.com/webp/gallery/1.webp`)
 	mountain := banana.UploadTexture(
@@ -41,6 +43,8 @@ func main() {
 	)
 
 	isFullScreen := false
+	// the mountain fades out and back in every four seconds
+	var fadeTicks int
 	exampleControls := func() {
 		if banana.IsKeyJustPressed(input.KeyA) {
 			isFullScreen = !isFullScreen
@@ -52,7 +56,9 @@ func main() {
 	}
 	banana.Run(func() {
 		exampleControls()
+		fadeTicks++
 	}, func() {
+		fade := float32(math.Abs(math.Cos(float64(fadeTicks) * banana.TickDuration.Seconds() * math.Pi / 4)))
 		banana.Clear(colornames.Black)
 		banana.RenderTexture(mountain, &banana.TextureRenderOptions{
 			X:          200,
@@ -63,6 +69,7 @@ func main() {
 			Height:     float32(mountainImage.Bounds().Dy()),
 			Rotation:   .9,
 			Scale:      1,
+			Alpha:      banana.Opacity(fade),
 		})
 		banana.RenderTexture(t, &banana.TextureRenderOptions{
 			X:          0,
@@ -39,10 +39,11 @@ func (d *Draw) buttonRender(options ButtonOptions) {
 
 	d.DrawRectangle(options.X, options.Y, options.Width, options.Height, &DrawOptions{
 		Style: Style{
-			FillColor:    fillColor,
-			OutlineColor: d.SecondaryColor,
-			OutlineSize:  2,
-			CornerRadius: 5,
+			FillColor:     fillColor,
+			OutlineColor:  d.SecondaryColor,
+			OutlineSize:   2,
+			CornerRadius:  5,
+			FillNineSlice: d.ButtonSkin,
 		},
 	})
 
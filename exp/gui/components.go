@@ -101,10 +101,11 @@ func (d *Draw) TextBox(options TextBoxOptions) {
 
 	d.DrawRectangle(options.X, options.Y, options.Width, height, &DrawOptions{
 		Style: Style{
-			FillColor:    options.BgColor,
-			OutlineColor: d.PrimaryColor,
-			OutlineSize:  2,
-			CornerRadius: 5,
+			FillColor:     options.BgColor,
+			OutlineColor:  d.PrimaryColor,
+			OutlineSize:   2,
+			CornerRadius:  5,
+			FillNineSlice: d.PanelSkin,
 		},
 	})
 
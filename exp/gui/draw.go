@@ -57,6 +57,16 @@ type (
 		OutlineColor color.Color
 		OutlineSize  int
 		CornerRadius int
+		// FillNineSlice replaces the flat fill of a rectangle with a nine-slice texture.
+		// The texture is tinted with FillColor, so skins drawn in white or gray follow the state colors of a widget.
+		FillNineSlice *NineSlice
+	}
+	// NineSlice is a texture whose corners stay fixed while its edges and center stretch.
+	NineSlice struct {
+		TextureID uint32
+		Insets    banana.Insets
+		Scale     float32
+		Tint      color.Color
 	}
 	ButtonOptions struct {
 		ID                  string
@@ -136,6 +146,15 @@ func (d *Draw) DrawTriangle(x1, y1, x2, y2, x3, y3 int, op *DrawOptions) {
 }
 
 func (d *Draw) DrawRectangle(x, y, width, height int, op *DrawOptions) {
+	if op.FillNineSlice != nil {
+		if op.OutlineSize > 0 {
+			outlineOp := *op
+			outlineOp.FillColor = op.OutlineColor
+			d.drawRoundedRectangle(x-op.OutlineSize, y-op.OutlineSize, width+2*op.OutlineSize, height+2*op.OutlineSize, op.CornerRadius+op.OutlineSize, &outlineOp)
+		}
+		d.drawNineSlice(x, y, width, height, op.FillNineSlice, op.FillColor)
+		return
+	}
 	if op.CornerRadius > 0 {
 		if op.OutlineSize > 0 && op.CornerRadius > 0 {
 			d.drawRoundedRectangleWithOutline(x, y, width, height, op.CornerRadius, op.OutlineSize, op)
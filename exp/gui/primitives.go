@@ -1,6 +1,8 @@
 package gui
 
 import (
+	"image/color"
+
 	"github.com/dfirebaugh/banana"
 )
 
@@ -54,6 +56,35 @@ func (d *Draw) drawRoundedRectangle(x, y, width, height, radius int, op *DrawOpt
 	})
 }
 
+func (d *Draw) drawNineSlice(x, y, width, height int, nineSlice *NineSlice, tint color.Color) {
+	banana.RenderNineSlice(nineSlice.TextureID, nineSlice.Insets, &banana.NineSliceRenderOptions{
+		X:      float32(x),
+		Y:      float32(y),
+		Width:  float32(width),
+		Height: float32(height),
+		Scale:  nineSlice.Scale,
+		Tint:   multiplyColors(nineSlice.Tint, tint),
+	})
+}
+
+// multiplyColors combines two tints. A nil color leaves the other one unchanged.
+func multiplyColors(a, b color.Color) color.Color {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return color.RGBA64{
+		R: uint16(ar * br / 0xffff),
+		G: uint16(ag * bg / 0xffff),
+		B: uint16(ab * bb / 0xffff),
+		A: uint16(aa * ba / 0xffff),
+	}
+}
+
 func (d *Draw) drawCircle(x, y, radius int, op *DrawOptions) {
 	banana.RenderShape(&banana.Circle{
 		X:      float32(x),
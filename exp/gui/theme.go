@@ -8,6 +8,9 @@ type Theme struct {
 	SecondaryColor  color.Color
 	TextColor       color.Color
 	HandleColor     color.Color
+	// ButtonSkin and PanelSkin are optional nine-slice textures drawn instead of flat fills and tinted with the fill color.
+	ButtonSkin *NineSlice
+	PanelSkin  *NineSlice
 }
 
 func (d *Draw) GetTheme() *Theme {
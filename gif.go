@@ -0,0 +1,110 @@
+package banana
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// minGIFDelay is the shortest delay browsers honor. Smaller delays, including none, are shown for 100ms.
+const minGIFDelay = 2
+
+// LoadGIF loads an animated GIF as an animation named after the file.
+func LoadGIF(path string) (*Animation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	a, err := DecodeGIF(f, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+// DecodeGIF decodes an animated GIF into an animation with the GIF's frame delays.
+// Frames are composited onto the full canvas following their disposal methods and uploaded to the texture atlas.
+// GIFs that loop forever become AnimationLoop and GIFs that play once become AnimationOnce.
+func DecodeGIF(r io.Reader, name string) (*Animation, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("gif has no frames")
+	}
+
+	ensureSetupCompletion()
+	a := &Animation{Name: name}
+	if g.LoopCount < 0 {
+		a.Mode = AnimationOnce
+	}
+	for i, img := range compositeGIF(g) {
+		delay := g.Delay[i]
+		if delay < minGIFDelay {
+			delay = 10
+		}
+		bounds := img.Bounds()
+		a.Frames = append(a.Frames, AnimationFrame{
+			Region:   NewRegion(UploadTexture(img), 0, 0, bounds.Dx(), bounds.Dy()),
+			Duration: time.Duration(delay) * 10 * time.Millisecond,
+		})
+	}
+	return a, nil
+}
+
+// compositeGIF returns every frame of g as it appears on the canvas.
+func compositeGIF(g *gif.GIF) []*image.RGBA {
+	width, height := g.Config.Width, g.Config.Height
+	if width == 0 || height == 0 {
+		// some encoders leave the logical screen empty
+		var bounds image.Rectangle
+		for _, img := range g.Image {
+			bounds = bounds.Union(img.Bounds())
+		}
+		width, height = bounds.Max.X, bounds.Max.Y
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	var previous *image.RGBA
+	frames := make([]*image.RGBA, len(g.Image))
+	for i, img := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas, previous)
+		}
+
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+		frames[i] = cloneRGBA(canvas, nil)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			// browsers clear to transparent rather than the background color
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous.Pix)
+		}
+	}
+	return frames
+}
+
+// cloneRGBA copies src into dst, allocating dst when it is nil.
+func cloneRGBA(src, dst *image.RGBA) *image.RGBA {
+	if dst == nil {
+		dst = image.NewRGBA(src.Rect)
+	}
+	copy(dst.Pix, src.Pix)
+	return dst
+}
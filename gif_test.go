@@ -0,0 +1,100 @@
+package banana
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	gifPalette = color.Palette{color.RGBA{}, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	gifNone    = color.RGBA{}
+	gifRed     = color.RGBA{255, 0, 0, 255}
+	gifGreen   = color.RGBA{0, 255, 0, 255}
+	gifBlue    = color.RGBA{0, 0, 255, 255}
+)
+
+// gifFrame returns a one pixel tall frame covering x0 to x0+len(indices) with the given palette indices.
+func gifFrame(x0 int, indices ...uint8) *image.Paletted {
+	img := image.NewPaletted(image.Rect(x0, 0, x0+len(indices), 1), gifPalette)
+	copy(img.Pix, indices)
+	return img
+}
+
+func gifRow(img *image.RGBA) []color.RGBA {
+	row := make([]color.RGBA, img.Rect.Dx())
+	for x := range row {
+		row[x] = img.RGBAAt(x, 0)
+	}
+	return row
+}
+
+func TestCompositeGIF(t *testing.T) {
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			gifFrame(0, 1, 1, 1, 1),
+			gifFrame(0, 2, 2),
+			gifFrame(1, 3, 3),
+			gifFrame(2, 2, 0),
+		},
+		Delay:    []int{10, 10, 10, 10},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalPrevious, 0},
+		Config:   image.Config{Width: 4, Height: 1},
+	}
+	want := [][]color.RGBA{
+		{gifRed, gifRed, gifRed, gifRed},
+		// drawn over the first frame, then cleared to transparent
+		{gifGreen, gifGreen, gifRed, gifRed},
+		// drawn over the cleared area, then restored to the canvas before it
+		{gifNone, gifBlue, gifBlue, gifRed},
+		// the transparent pixel keeps what is below it
+		{gifNone, gifNone, gifGreen, gifRed},
+	}
+
+	frames := compositeGIF(g)
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(want))
+	}
+	for i, frame := range frames {
+		if got := gifRow(frame); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("frame %d = %v, want %v", i, got, want[i])
+		}
+	}
+
+	// without a logical screen size the canvas covers every frame
+	g.Config = image.Config{}
+	if got := compositeGIF(g)[0].Rect; got != image.Rect(0, 0, 4, 1) {
+		t.Errorf("canvas without a logical screen = %v, want %v", got, image.Rect(0, 0, 4, 1))
+	}
+}
+
+func TestDecodeGIF(t *testing.T) {
+	var buf bytes.Buffer
+	err := gif.EncodeAll(&buf, &gif.GIF{
+		Image:     []*image.Paletted{gifFrame(0, 1, 1), gifFrame(0, 2, 2)},
+		Delay:     []int{0, 5},
+		LoopCount: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := DecodeGIF(&buf, "blink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "blink" || a.Mode != AnimationOnce {
+		t.Errorf("animation %q has mode %v, want blink played once", a.Name, a.Mode)
+	}
+	var durations []time.Duration
+	for _, frame := range a.Frames {
+		durations = append(durations, frame.Duration)
+	}
+	if want := []time.Duration{100 * time.Millisecond, 50 * time.Millisecond}; !reflect.DeepEqual(durations, want) {
+		t.Errorf("frame durations = %v, want %v", durations, want)
+	}
+}
@@ -8,11 +8,9 @@ require (
 	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
 	github.com/sirupsen/logrus v1.9.3
 	golang.org/x/image v0.19.0
+	golang.org/x/sys v0.19.0
 )
 
-require (
-	golang.org/x/sys v0.19.0 // indirect
-	golang.org/x/text v0.17.0 // indirect
-)
+require golang.org/x/text v0.17.0 // indirect
 
 require github.com/go-gl/glfw/v3.3/glfw v0.0.0-20240506104042-037f3cc74f2a
@@ -0,0 +1,83 @@
+// Package diag routes the diagnostics of the renderer, including the GL driver's debug output, through one logger.
+package diag
+
+import (
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Level is the severity of a message.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelOff silences every message.
+	LevelOff
+)
+
+// Logger receives diagnostics. *logrus.Logger satisfies it.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+}
+
+var (
+	logger Logger = newDefaultLogger()
+	level         = LevelInfo
+)
+
+// newDefaultLogger lets every message through, so the level set with SetLevel is the only filter.
+func newDefaultLogger() Logger {
+	l := logrus.New()
+	l.SetOutput(os.Stderr)
+	l.SetLevel(logrus.DebugLevel)
+	return l
+}
+
+// SetLogger replaces the logger. A nil logger restores the default one, which writes to stderr.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = newDefaultLogger()
+	}
+	logger = l
+}
+
+// SetLevel drops messages below a severity. The default is LevelInfo.
+func SetLevel(l Level) {
+	level = l
+}
+
+// Enabled reports whether messages of a severity are logged.
+func Enabled(l Level) bool {
+	return l >= level && level != LevelOff
+}
+
+func Debugf(format string, args ...any) {
+	if Enabled(LevelDebug) {
+		logger.Debugf(format, args...)
+	}
+}
+
+func Infof(format string, args ...any) {
+	if Enabled(LevelInfo) {
+		logger.Infof(format, args...)
+	}
+}
+
+func Warnf(format string, args ...any) {
+	if Enabled(LevelWarn) {
+		logger.Warnf(format, args...)
+	}
+}
+
+func Errorf(format string, args ...any) {
+	if Enabled(LevelError) {
+		logger.Errorf(format, args...)
+	}
+}
@@ -6,11 +6,10 @@ import (
 	"image/color"
 	"image/draw"
 	"image/png"
-	"log"
 	"math"
 	"os"
 
-	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/dfirebaugh/banana/graphics/diag"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/sfnt"
 	"golang.org/x/image/math/fixed"
@@ -31,17 +30,12 @@ type Glyph struct {
 
 type Font struct {
 	sfntFont    *sfnt.Font
-	Atlas       uint32
 	Glyphs      map[rune]*Glyph
 	AtlasWidth  int
 	AtlasHeight int
 	atlasImage  *image.RGBA
 }
 
-func (font *Font) Destroy() {
-	gl.DeleteTextures(1, &font.Atlas)
-}
-
 func (f *Font) Image() image.Image {
 	return f.atlasImage
 }
@@ -63,6 +57,55 @@ func (f *Font) GetKerning(r1, r2 rune, ppem fixed.Int26_6) (fixed.Int26_6, error
 	return kern, nil
 }
 
+// Layout places the glyphs of a line of text starting at x, y for a size in points.
+// fn is called for every glyph with the rectangle it covers in pixels, y pointing down.
+// Runes without a glyph are skipped.
+func (f *Font) Layout(text string, x, y, size float32, fn func(glyph *Glyph, x, y, width, height float32)) {
+	const dpi = 96.0
+	scale := size / 72.0 * dpi / 32.0
+	ppem := fixed.Int26_6(32 << 6)
+
+	cursorX := x
+	var prevRune rune
+	for _, r := range text {
+		if r == '\n' {
+			continue
+		}
+
+		glyph, exists := f.Glyphs[r]
+		if !exists {
+			diag.Warnf("Glyph for rune '%c' not found", r)
+			continue
+		}
+
+		if prevRune != 0 {
+			kern, err := f.GetKerning(prevRune, r, ppem)
+			if err == nil {
+				cursorX += float32(kern) / 64.0 * scale
+			}
+		}
+
+		xpos := cursorX + glyph.BearingX*scale
+		ypos := y - (glyph.SizeHeight-glyph.BearingY)*scale
+		ypos = bruteForceFixFloaters(glyph.Rune, ypos, size)
+
+		fn(glyph, xpos, ypos, glyph.SizeWidth*scale, glyph.SizeHeight*scale)
+
+		cursorX += glyph.AdvanceWidth * scale
+		prevRune = r
+	}
+}
+
+func bruteForceFixFloaters(r rune, ypos float32, ptSize float32) float32 {
+	if r == '^' || r == '\'' {
+		return ypos + ptSize
+	}
+	if r == '\'' || r == '"' || r == '`' {
+		return ypos + ptSize/2
+	}
+	return ypos
+}
+
 func LoadFont(fontData []byte) (*Font, error) {
 	sfntFont, err := sfnt.Parse(fontData)
 	if err != nil {
@@ -91,7 +134,7 @@ func LoadFont(fontData []byte) (*Font, error) {
 
 		glyphIndex, err := f.sfntFont.GlyphIndex(&buf, r)
 		if err != nil || glyphIndex == 0 {
-			log.Printf("Glyph not found for rune '%c'", r)
+			diag.Warnf("Glyph not found for rune '%c'", r)
 			continue
 		}
 
@@ -99,25 +142,25 @@ func LoadFont(fontData []byte) (*Font, error) {
 
 		advance, err := f.sfntFont.GlyphAdvance(&buf, glyphIndex, ppem, font.HintingNone)
 		if err != nil {
-			log.Printf("Failed to get advance for rune '%c': %v", r, err)
+			diag.Warnf("Failed to get advance for rune '%c': %v", r, err)
 			continue
 		}
 
 		bounds, _, err := f.sfntFont.GlyphBounds(&buf, glyphIndex, ppem, font.HintingNone)
 		if err != nil {
-			log.Printf("Failed to get bounds for rune '%c': %v", r, err)
+			diag.Warnf("Failed to get bounds for rune '%c': %v", r, err)
 			continue
 		}
 
 		segments, err := f.sfntFont.LoadGlyph(&buf, glyphIndex, ppem, nil)
 		if err != nil {
-			log.Printf("Failed to load glyph '%c': %v", r, err)
+			diag.Warnf("Failed to load glyph '%c': %v", r, err)
 			continue
 		}
 
 		rWidth, rHeight, img, err := rasterizeGlyph(segments)
 		if err != nil {
-			log.Printf("Failed to rasterize glyph '%c': %v", r, err)
+			diag.Warnf("Failed to rasterize glyph '%c': %v", r, err)
 			continue
 		}
 
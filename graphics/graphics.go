@@ -25,6 +25,58 @@ type TextureRenderOptions struct {
 	Width, Height               float32
 	FlipX, FlipY                bool
 	Rotation                    float32
+	Tint                        color.Color
+	Alpha                       *float32
+	OriginX, OriginY            float32
+	SkewX, SkewY                float32
+}
+
+// Insets are the distances in texture pixels from each edge of a texture
+// to the stretchable center of a nine-slice.
+type Insets struct {
+	Left, Top, Right, Bottom float32
+}
+
+type NineSliceRenderOptions struct {
+	X, Y          float32
+	Width, Height float32
+	// Scale is applied to the fixed corners and edges. The zero value is treated as 1.
+	Scale float32
+	Tint  color.Color
+	Alpha *float32
+}
+
+type TiledTextureRenderOptions struct {
+	X, Y          float32
+	Width, Height float32
+	// RectX, RectY, RectWidth and RectHeight select the region of the texture that is tiled.
+	// A zero sized rect tiles the whole texture.
+	RectX, RectY          float32
+	RectWidth, RectHeight float32
+	// Scale is the size of a tile relative to the region. The zero value is treated as 1.
+	Scale float32
+	// OffsetX and OffsetY scroll the tiles, in texture pixels.
+	OffsetX, OffsetY float32
+	WrapMode         WrapMode
+	Tint             color.Color
+	Alpha            *float32
+}
+
+// TextureFilter selects how a texture is sampled when it is scaled.
+type TextureFilter int
+
+const (
+	FilterNearest TextureFilter = iota
+	FilterLinear
+)
+
+type TextureOptions struct {
+	Filter TextureFilter
+	// Mipmaps generates a mipmap chain so the texture stays smooth when drawn smaller than its size.
+	Mipmaps bool
+	// Standalone stores the texture in its own GL texture instead of the shared atlas.
+	// Textures that are large, linearly filtered or mipmapped are always standalone.
+	Standalone bool
 }
 
 type TextRenderOptions struct {
@@ -40,6 +92,24 @@ const (
 	OP_CODE_RECT    = 3.0
 	OP_CODE_TEXT    = 4.0
 	OP_CODE_TEXTURE = 5.0
+	// OP_CODE_TEXTURE_TILED samples a texture region repeatedly.
+	// TexCoord is measured in tiles and wrapped in the shader against TexRect.
+	OP_CODE_TEXTURE_TILED = 6.0
+	// OP_CODE_TEXTURE_INDEXED samples a palette index and looks its color up in a palette texture.
+	// The palette is sampled from the slot in FontIndex.
+	OP_CODE_TEXTURE_INDEXED = 7.0
+	// OP_CODE_CUSTOM_BASE is the op code of the first registered custom shape.
+	// Custom shapes receive their four parameters in TexRect.
+	OP_CODE_CUSTOM_BASE = 100.0
+)
+
+// WrapMode controls how tiled texture coordinates outside of a single tile are resolved.
+type WrapMode int
+
+const (
+	WrapRepeat WrapMode = iota
+	WrapMirror
+	WrapClamp
 )
 
 type Vertex struct {
@@ -55,6 +125,32 @@ type Vertex struct {
 	TexCoord     [2]float32
 	TextureIndex float32
 	FontIndex    float32
+	TexRect      [4]float32
+	WrapMode     float32
+	// StrokeWidth and StrokeColor outline shapes drawn from a signed distance function.
+	StrokeWidth float32
+	StrokeColor [4]float32
+}
+
+// FramebufferFormat is the storage format of a framebuffer's color texture.
+type FramebufferFormat int
+
+const (
+	FramebufferRGBA8 FramebufferFormat = iota
+	// FramebufferRGBA16F and FramebufferRGBA32F store unclamped floating point color, for HDR and accumulation.
+	FramebufferRGBA16F
+	FramebufferRGBA32F
+	// Single channel formats are sampled as grayscale with full alpha.
+	FramebufferR8
+	FramebufferR16F
+	FramebufferR32F
+)
+
+type FramebufferOptions struct {
+	// Samples enables multisampling when greater than 1. It is clamped to what the driver supports.
+	// The framebuffer is resolved into its texture before it is sampled.
+	Samples int
+	Format  FramebufferFormat
 }
 
 type Framebuffer interface {
@@ -70,6 +166,18 @@ type Framebuffer interface {
 	Draw(x, y, width, height int)
 }
 
+// Shader is a program compiled from user supplied GLSL.
+// Uniform setters can be called at any time; the values are applied whenever the shader is used.
+type Shader interface {
+	SetFloat(name string, v float32)
+	SetInt(name string, v int32)
+	SetVec2(name string, x, y float32)
+	SetVec3(name string, x, y, z float32)
+	SetVec4(name string, x, y, z, w float32)
+	SetMat4(name string, m [16]float32)
+	Destroy()
+}
+
 type GraphicsBackend interface {
 	WindowManager
 	InputManager
@@ -83,10 +191,19 @@ type GraphicsBackend interface {
 	RenderText(text string, options *TextRenderOptions)
 	LoadFont(fontPath []byte) (Font, error)
 	SwapBuffers()
+	// ReadScreen returns a copy of the frame drawn to the screen. It is called after Draw and before SwapBuffers.
+	ReadScreen() *image.RGBA
 	GetViewportSize() (int, int)
 	AddFramebuffer(width, height int) (Framebuffer, error)
+	AddFramebufferWithOptions(width, height int, options FramebufferOptions) (Framebuffer, error)
 	RenderFramebuffer(fb Framebuffer, options *TextureRenderOptions)
 	BindFramebuffer(fb Framebuffer)
+	NewShader(vertexSource, fragmentSource string) (Shader, error)
+	NewShaderFromFiles(vertexPath, fragmentPath string) (Shader, error)
+	EnableShaderHotReload(dir string) error
+	SetDebugOutput(enabled bool)
+	RegisterShape(name string, sdfSource string) (OpCode, error)
+	SetPostProcess(shaders []Shader)
 	UnbindFramebuffer()
 	Begin()
 	End()
@@ -94,8 +211,18 @@ type GraphicsBackend interface {
 
 type TextureManager interface {
 	RenderTexture(textureHandle uint32, options *TextureRenderOptions)
+	RenderNineSlice(textureHandle uint32, insets Insets, options *NineSliceRenderOptions)
+	RenderTiledTexture(textureHandle uint32, options *TiledTextureRenderOptions)
 	UploadTexture(image.Image) uint32
+	UploadTextureWithOptions(img image.Image, options TextureOptions) uint32
 	UpdateTexture(textureID uint32, img image.Image, xOffset, yOffset int)
+	DeleteTexture(textureID uint32)
+	CompactAtlas()
+	// Indexed textures hold 8-bit palette indices that are looked up in a palette of 256 colors when drawn.
+	UploadIndexedTexture(img *image.Paletted) uint32
+	UpdateIndexedTexture(textureID uint32, img *image.Paletted, xOffset, yOffset int)
+	UpdatePalette(textureID uint32, palette color.Palette)
+	RenderIndexedTexture(textureID uint32, options *TextureRenderOptions)
 }
 
 type WindowManager interface {
@@ -0,0 +1,155 @@
+package opengl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// textureBinding records that the textured vertices from first up to the next binding
+// sample from glTextureID. Atlas pages, standalone textures, font pages and framebuffers
+// are all bound the same way.
+type textureBinding struct {
+	first       int
+	glTextureID uint32
+	// palette is the GL texture indexed vertices look their colors up in, or 0.
+	palette uint32
+}
+
+// drawBatch is a run of vertices drawn with one set of textures bound.
+// textures is indexed by sampler slot.
+type drawBatch struct {
+	first, count int
+	textures     []uint32
+}
+
+// useTexture records that the vertices queued next sample from a GL texture.
+// It must be called before the vertices are appended.
+func (renderer *Renderer) useTexture(glTextureID uint32) {
+	renderer.useIndexedTexture(glTextureID, 0)
+}
+
+// useIndexedTexture records that the vertices queued next sample indices from one GL texture
+// and look them up in the palette of another.
+func (renderer *Renderer) useIndexedTexture(glTextureID, palette uint32) {
+	binding := textureBinding{first: renderer.VertexCount, glTextureID: glTextureID, palette: palette}
+	if n := len(renderer.bindings); n > 0 {
+		last := &renderer.bindings[n-1]
+		if last.glTextureID == glTextureID && last.palette == palette {
+			return
+		}
+		if last.first == renderer.VertexCount {
+			*last = binding
+			return
+		}
+	}
+	renderer.bindings = append(renderer.bindings, binding)
+}
+
+// querySamplerSlots sizes the sampler array of the uber shader to the texture units of the GPU.
+// OpenGL 3.3 and OpenGL ES 3.0 only guarantee 16.
+func (renderer *Renderer) querySamplerSlots() {
+	var units int32
+	gl.GetIntegerv(gl.MAX_TEXTURE_IMAGE_UNITS, &units)
+	renderer.maxSlots = MaxSamplers
+	if units > 0 {
+		renderer.maxSlots = min(int(units), MaxSamplers)
+	}
+}
+
+// samplerSource declares the sampler array and sampleSlot, which reads from a slot.
+// GLSL 3.30 and GLSL ES 3.00 can only index sampler arrays with constants, so every slot gets a case.
+func (renderer *Renderer) samplerSource() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "uniform sampler2D samplers[%d];\n\nvec4 sampleSlot(int slot, vec2 uv) {\n    switch (slot) {\n", renderer.maxSlots)
+	for slot := 0; slot < renderer.maxSlots; slot++ {
+		fmt.Fprintf(&b, "    case %d: return texture(samplers[%d], uv);\n", slot, slot)
+	}
+	b.WriteString("    }\n    return vec4(0.0);\n}\n")
+	return b.String()
+}
+
+// initSamplers points every sampler of the shader at the texture unit of the same index.
+func (renderer *Renderer) initSamplers() {
+	gl.UseProgram(renderer.ShaderProgram)
+	missing := 0
+	for slot := 0; slot < renderer.maxSlots; slot++ {
+		samplerName := fmt.Sprintf("samplers[%d]\x00", slot)
+		location := gl.GetUniformLocation(renderer.ShaderProgram, gl.Str(samplerName))
+		if location == -1 {
+			missing++
+			continue
+		}
+		gl.Uniform1i(location, int32(slot))
+	}
+	if missing > 0 {
+		diag.Errorf("The uber shader is missing %d of its %d samplers, so textures and text will not draw. "+
+			"A replaced primitive.frag has to keep the %q line and read textures through sampleSlot", missing, renderer.maxSlots, samplersMarker)
+	}
+}
+
+// buildBatches assigns sampler slots to the textures used by the queued vertices
+// and writes the slots into the vertices. A new batch is started whenever a texture
+// is needed and every slot of the current batch is taken.
+func (renderer *Renderer) buildBatches() []drawBatch {
+	if renderer.VertexCount == 0 {
+		return nil
+	}
+
+	batches := []drawBatch{{}}
+	slots := make(map[uint32]int)
+	current := &batches[0]
+
+	for i, binding := range renderer.bindings {
+		end := renderer.VertexCount
+		if i+1 < len(renderer.bindings) {
+			end = renderer.bindings[i+1].first
+		}
+
+		needed := 0
+		for _, id := range []uint32{binding.glTextureID, binding.palette} {
+			if _, ok := slots[id]; !ok && id != 0 {
+				needed++
+			}
+		}
+		if len(current.textures)+needed > renderer.maxSlots {
+			current.count = binding.first - current.first
+			batches = append(batches, drawBatch{first: binding.first})
+			current = &batches[len(batches)-1]
+			clear(slots)
+		}
+		assign := func(id uint32) int {
+			slot, ok := slots[id]
+			if !ok {
+				slot = len(current.textures)
+				slots[id] = slot
+				current.textures = append(current.textures, id)
+			}
+			return slot
+		}
+		slot := assign(binding.glTextureID)
+		paletteSlot := 0
+		if binding.palette != 0 {
+			paletteSlot = assign(binding.palette)
+		}
+
+		for v := binding.first; v < end; v++ {
+			vertex := &renderer.Vertices[v]
+			switch vertex.OpCode {
+			case graphics.OP_CODE_TEXT:
+				vertex.FontIndex = float32(slot)
+			case graphics.OP_CODE_TEXTURE, graphics.OP_CODE_TEXTURE_TILED:
+				vertex.TextureIndex = float32(slot)
+			case graphics.OP_CODE_TEXTURE_INDEXED:
+				vertex.TextureIndex = float32(slot)
+				vertex.FontIndex = float32(paletteSlot)
+			}
+		}
+	}
+	current.count = renderer.VertexCount - current.first
+
+	return batches
+}
@@ -0,0 +1,91 @@
+package opengl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/opengl/shaders"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+const (
+	customSDFFunctionsMarker = "// @custom-sdf-functions"
+	customSDFDispatchMarker  = "// @custom-sdf-dispatch"
+	samplersMarker           = "// @samplers"
+)
+
+// customShape is a signed distance function supplied by the user and drawn under its own op code.
+type customShape struct {
+	name   string
+	opCode graphics.OpCode
+	source string
+}
+
+// RegisterShape adds a shape to the uber shader. sdfSource is the body of a GLSL function
+//
+//	float sd(vec2 p, vec2 size, float radius, vec4 params)
+//
+// that returns the signed distance in pixels from p to the edge of the shape, negative inside.
+// p is relative to the center of the shape with y pointing down. The shape is filled, anti-aliased
+// and stroked like the built in shapes. The shader is recompiled, and on failure the shape is not added.
+func (renderer *Renderer) RegisterShape(name string, sdfSource string) (graphics.OpCode, error) {
+	for _, shape := range renderer.customShapes {
+		if shape.name == name {
+			return 0, fmt.Errorf("shape %q is already registered", name)
+		}
+	}
+
+	shape := customShape{
+		name:   name,
+		opCode: graphics.OpCode(graphics.OP_CODE_CUSTOM_BASE + len(renderer.customShapes)),
+		source: sdfSource,
+	}
+	renderer.customShapes = append(renderer.customShapes, shape)
+
+	if renderer.ShaderProgram != 0 {
+		if err := renderer.rebuildProgram(); err != nil {
+			renderer.customShapes = renderer.customShapes[:len(renderer.customShapes)-1]
+			return 0, fmt.Errorf("shape %q: %w", name, err)
+		}
+	}
+	return shape.opCode, nil
+}
+
+// fragmentShaderSource returns the uber shader with every custom shape spliced in.
+func (renderer *Renderer) fragmentShaderSource() string {
+	return renderer.spliceShapes(renderer.fragmentBase)
+}
+
+func (renderer *Renderer) spliceShapes(source string) string {
+	var functions, dispatch strings.Builder
+	for i, shape := range renderer.customShapes {
+		fmt.Fprintf(&functions, "// %s\nfloat sdCustom%d(vec2 p, vec2 size, float radius, vec4 params) {\n%s\n}\n\n", shape.name, i, shape.source)
+		fmt.Fprintf(&dispatch, "    if (op_code == %.1f) {\n        fragColor = shadeSDF(sdCustom%d(vec2(p.x, -p.y), vec2(width, height), radius, tex_rect));\n    }\n", float32(shape.opCode), i)
+	}
+
+	source = strings.Replace(source, samplersMarker, renderer.samplerSource(), 1)
+	source = strings.Replace(source, customSDFFunctionsMarker, functions.String(), 1)
+	source = strings.Replace(source, customSDFDispatchMarker, dispatch.String(), 1)
+	return source
+}
+
+// rebuildProgram compiles the uber shader again and replaces the current program when it succeeds.
+func (renderer *Renderer) rebuildProgram() error {
+	program, err := renderer.compileUberShader()
+	if err != nil {
+		return err
+	}
+	gl.DeleteProgram(renderer.ShaderProgram)
+	renderer.ShaderProgram = program
+	renderer.initSamplers()
+	return nil
+}
+
+// compileUberShader translates the uber shader for the current context and compiles it.
+func (renderer *Renderer) compileUberShader() (uint32, error) {
+	return newShaderProgram(
+		shaders.Translate(renderer.vertexSource, renderer.glsl),
+		shaders.Translate(renderer.fragmentShaderSource(), renderer.glsl),
+	)
+}
@@ -0,0 +1,144 @@
+package opengl
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/dfirebaugh/banana/graphics/opengl/shaders"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// SetDebugOutput routes the driver's debug messages (KHR_debug) through the diag logger.
+// High severity messages are logged as errors, medium as warnings, low as info and notifications as debug.
+// Drivers only promise to report everything on a debug context, see window.DebugContext.
+func (renderer *Renderer) SetDebugOutput(enabled bool) {
+	if !glfw.ExtensionSupported("GL_KHR_debug") {
+		if enabled {
+			diag.Warnf("The driver does not support GL_KHR_debug, so GL errors can not be reported")
+		}
+		return
+	}
+	if !enabled {
+		gl.Disable(gl.DEBUG_OUTPUT)
+		return
+	}
+	// OpenGL ES only exposes the suffixed entry points of the extension
+	if renderer.glsl == shaders.GLSLES300 {
+		gl.DebugMessageCallbackKHR(debugMessage, nil)
+	} else {
+		gl.DebugMessageCallback(debugMessage, nil)
+	}
+	gl.Enable(gl.DEBUG_OUTPUT)
+	// messages are delivered on the thread that made the call, so they can be traced back to it
+	gl.Enable(gl.DEBUG_OUTPUT_SYNCHRONOUS)
+}
+
+func debugMessage(source, gltype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+	text := fmt.Sprintf("GL %s %s %d: %s", debugSourceName(source), debugTypeName(gltype), id, message)
+	switch severity {
+	case gl.DEBUG_SEVERITY_HIGH:
+		diag.Errorf("%s", text)
+	case gl.DEBUG_SEVERITY_MEDIUM:
+		diag.Warnf("%s", text)
+	case gl.DEBUG_SEVERITY_LOW:
+		diag.Infof("%s", text)
+	default:
+		diag.Debugf("%s", text)
+	}
+}
+
+func debugSourceName(source uint32) string {
+	switch source {
+	case gl.DEBUG_SOURCE_API:
+		return "api"
+	case gl.DEBUG_SOURCE_WINDOW_SYSTEM:
+		return "window system"
+	case gl.DEBUG_SOURCE_SHADER_COMPILER:
+		return "shader compiler"
+	case gl.DEBUG_SOURCE_THIRD_PARTY:
+		return "third party"
+	case gl.DEBUG_SOURCE_APPLICATION:
+		return "application"
+	default:
+		return "other"
+	}
+}
+
+func debugTypeName(gltype uint32) string {
+	switch gltype {
+	case gl.DEBUG_TYPE_ERROR:
+		return "error"
+	case gl.DEBUG_TYPE_DEPRECATED_BEHAVIOR:
+		return "deprecated behavior"
+	case gl.DEBUG_TYPE_UNDEFINED_BEHAVIOR:
+		return "undefined behavior"
+	case gl.DEBUG_TYPE_PORTABILITY:
+		return "portability"
+	case gl.DEBUG_TYPE_PERFORMANCE:
+		return "performance"
+	case gl.DEBUG_TYPE_MARKER:
+		return "marker"
+	default:
+		return "message"
+	}
+}
+
+// glErrorName names the codes returned by glGetError.
+func glErrorName(code uint32) string {
+	switch code {
+	case gl.INVALID_ENUM:
+		return "GL_INVALID_ENUM"
+	case gl.INVALID_VALUE:
+		return "GL_INVALID_VALUE"
+	case gl.INVALID_OPERATION:
+		return "GL_INVALID_OPERATION"
+	case gl.INVALID_FRAMEBUFFER_OPERATION:
+		return "GL_INVALID_FRAMEBUFFER_OPERATION"
+	case gl.OUT_OF_MEMORY:
+		return "GL_OUT_OF_MEMORY"
+	default:
+		return fmt.Sprintf("0x%x", code)
+	}
+}
+
+// checkGLErrors logs the errors GL recorded since it was last asked. GL keeps errors until they are read,
+// so this also catches errors when debug output is off.
+func checkGLErrors(during string) {
+	for code := gl.GetError(); code != gl.NO_ERROR; code = gl.GetError() {
+		diag.Errorf("%s during %s. Enable debug output to find the call that caused it", glErrorName(code), during)
+	}
+}
+
+// framebufferStatusError explains why a framebuffer is incomplete.
+func framebufferStatusError(status uint32) error {
+	switch status {
+	case gl.FRAMEBUFFER_COMPLETE:
+		return nil
+	case gl.FRAMEBUFFER_UNSUPPORTED:
+		return fmt.Errorf("framebuffer is unsupported; the color format is likely not renderable on this GPU, try FramebufferRGBA8")
+	case gl.FRAMEBUFFER_INCOMPLETE_MULTISAMPLE:
+		return fmt.Errorf("framebuffer attachments disagree on the sample count; try fewer samples")
+	case gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:
+		return fmt.Errorf("framebuffer has an incomplete attachment; check that its size is above zero and within GL_MAX_RENDERBUFFER_SIZE")
+	case gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT:
+		return fmt.Errorf("framebuffer has no attachments")
+	case gl.FRAMEBUFFER_UNDEFINED:
+		return fmt.Errorf("the default framebuffer does not exist; the window has no drawable surface")
+	default:
+		return fmt.Errorf("framebuffer is not complete (status 0x%x)", status)
+	}
+}
+
+// validate checks the state Init leaves behind, logging what is wrong and how to fix it.
+func (renderer *Renderer) validate() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if err := framebufferStatusError(gl.CheckFramebufferStatus(gl.FRAMEBUFFER)); err != nil {
+		diag.Errorf("The window can not be drawn to: %v", err)
+	}
+	if renderer.maxSlots < MaxSamplers {
+		diag.Infof("The GPU has %d texture units; draws using more textures are split into batches", renderer.maxSlots)
+	}
+	checkGLErrors("renderer initialization")
+}
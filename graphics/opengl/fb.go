@@ -5,48 +5,87 @@ import (
 	"image/color"
 
 	"github.com/dfirebaugh/banana/graphics"
-	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
 type Framebuffer struct {
-	ID         uint32
+	// ID is the framebuffer that is rendered into. It is multisampled when Options.Samples is above 1.
+	ID uint32
+	// TextureID is the texture the framebuffer is sampled from.
 	TextureID  uint32
 	RenderID   uint32
 	Width      int
 	Height     int
 	ClearColor [4]float32
-	renderer   *FramebufferRenderer
+	Options    graphics.FramebufferOptions
+	// resolveID and colorID are the framebuffer the multisampled color is resolved into
+	// and the multisampled color buffer itself. Both are 0 without multisampling.
+	resolveID uint32
+	colorID   uint32
+	renderer  *FramebufferRenderer
+}
+
+type textureFormat struct {
+	internalFormat int32
+	format         uint32
+	dataType       uint32
+}
+
+func framebufferTextureFormat(format graphics.FramebufferFormat) textureFormat {
+	switch format {
+	case graphics.FramebufferRGBA16F:
+		return textureFormat{gl.RGBA16F, gl.RGBA, gl.FLOAT}
+	case graphics.FramebufferRGBA32F:
+		return textureFormat{gl.RGBA32F, gl.RGBA, gl.FLOAT}
+	case graphics.FramebufferR8:
+		return textureFormat{gl.R8, gl.RED, gl.UNSIGNED_BYTE}
+	case graphics.FramebufferR16F:
+		return textureFormat{gl.R16F, gl.RED, gl.FLOAT}
+	case graphics.FramebufferR32F:
+		return textureFormat{gl.R32F, gl.RED, gl.FLOAT}
+	default:
+		return textureFormat{gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE}
+	}
+}
+
+// supportedSamples clamps a sample count to what the driver supports. Counts of 1 or less disable multisampling.
+func supportedSamples(samples int) int {
+	if samples <= 1 {
+		return 0
+	}
+	var maxSamples int32
+	gl.GetIntegerv(gl.MAX_SAMPLES, &maxSamples)
+	return min(samples, int(maxSamples))
 }
 
 func NewFramebuffer(width, height int, textureManager *TextureManager, mainRenderer *Renderer) (*Framebuffer, error) {
+	return NewFramebufferWithOptions(width, height, graphics.FramebufferOptions{}, textureManager, mainRenderer)
+}
+
+func NewFramebufferWithOptions(width, height int, options graphics.FramebufferOptions, textureManager *TextureManager, mainRenderer *Renderer) (*Framebuffer, error) {
 	var fb Framebuffer
 	fb.Width = width
 	fb.Height = height
+	fb.Options = options
+	fb.Options.Samples = supportedSamples(options.Samples)
 
 	gl.GenFramebuffers(1, &fb.ID)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.ID)
-
 	gl.GenTextures(1, &fb.TextureID)
-	println("textureID: ", fb.TextureID)
-	gl.BindTexture(gl.TEXTURE_2D, fb.TextureID)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, fb.TextureID, 0)
-
+	diag.Debugf("Created framebuffer with texture ID %d", fb.TextureID)
 	gl.GenRenderbuffers(1, &fb.RenderID)
-	gl.BindRenderbuffer(gl.RENDERBUFFER, fb.RenderID)
-	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, int32(width), int32(height))
-	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, fb.RenderID)
+	if fb.multisampled() {
+		gl.GenFramebuffers(1, &fb.resolveID)
+		gl.GenRenderbuffers(1, &fb.colorID)
+	}
 
-	if gl.CheckFramebufferStatus(gl.FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
-		return nil, fmt.Errorf("framebuffer is not complete")
+	if err := fb.allocate(); err != nil {
+		fb.Destroy()
+		return nil, err
 	}
 
 	textureManager.RegisterFramebufferTexture(fb.TextureID, width, height)
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-
 	fbRenderer, err := NewFramebufferRenderer(mainRenderer, &fb)
 	if err != nil {
 		return nil, err
@@ -55,6 +94,72 @@ func NewFramebuffer(width, height int, textureManager *TextureManager, mainRende
 	return &fb, nil
 }
 
+func (fb *Framebuffer) multisampled() bool {
+	return fb.Options.Samples > 1
+}
+
+// allocate creates the storage of every attachment at the current size.
+func (fb *Framebuffer) allocate() error {
+	format := framebufferTextureFormat(fb.Options.Format)
+	width, height := int32(fb.Width), int32(fb.Height)
+	samples := int32(fb.Options.Samples)
+
+	gl.BindTexture(gl.TEXTURE_2D, fb.TextureID)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, format.internalFormat, width, height, 0, format.format, format.dataType, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	if format.format == gl.RED {
+		// set per channel, since OpenGL ES has no TEXTURE_SWIZZLE_RGBA
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_R, gl.RED)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_G, gl.RED)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_B, gl.RED)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_A, gl.ONE)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.ID)
+	if fb.multisampled() {
+		gl.BindRenderbuffer(gl.RENDERBUFFER, fb.colorID)
+		gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, samples, uint32(format.internalFormat), width, height)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, fb.colorID)
+	} else {
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, fb.TextureID, 0)
+	}
+
+	gl.BindRenderbuffer(gl.RENDERBUFFER, fb.RenderID)
+	if fb.multisampled() {
+		gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, samples, gl.DEPTH24_STENCIL8, width, height)
+	} else {
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, width, height)
+	}
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, fb.RenderID)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	err := framebufferStatusError(gl.CheckFramebufferStatus(gl.FRAMEBUFFER))
+	if err == nil && fb.multisampled() {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, fb.resolveID)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, fb.TextureID, 0)
+		if err = framebufferStatusError(gl.CheckFramebufferStatus(gl.FRAMEBUFFER)); err != nil {
+			err = fmt.Errorf("resolve target: %w", err)
+		}
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	return err
+}
+
+// resolve copies the multisampled color into the texture the framebuffer is sampled from.
+func (fb *Framebuffer) resolve() {
+	if !fb.multisampled() {
+		return
+	}
+	width, height := int32(fb.Width), int32(fb.Height)
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, fb.ID)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, fb.resolveID)
+	gl.BlitFramebuffer(0, 0, width, height, 0, 0, width, height, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
 func (fb *Framebuffer) GetTextureID() uint32 {
 	return fb.TextureID
 }
@@ -80,34 +185,32 @@ func (fb *Framebuffer) Unbind() {
 	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
 }
 
+// Clear drops what was queued for the framebuffer this frame and clears it when the frame is drawn.
 func (fb *Framebuffer) Clear(c color.Color) {
-	rgba := toRGBA(c)
-	fb.ClearColor = rgba
-	gl.ClearColor(rgba[0], rgba[1], rgba[2], rgba[3])
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+	fb.ClearColor = toRGBA(c)
+	fb.renderer.clearFramebuffer(fb, c)
 }
 
 func (fb *Framebuffer) Resize(width, height int) {
 	fb.Width = width
 	fb.Height = height
 
-	gl.BindTexture(gl.TEXTURE_2D, fb.TextureID)
-	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
-
-	gl.BindRenderbuffer(gl.RENDERBUFFER, fb.RenderID)
-	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, int32(width), int32(height))
-
-	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.ID)
-	if gl.CheckFramebufferStatus(gl.FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
-		fmt.Println("framebuffer is not complete after resize")
+	if err := fb.allocate(); err != nil {
+		diag.Errorf("Failed to resize framebuffer to %dx%d: %v", width, height, err)
 	}
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
 }
 
 func (fb *Framebuffer) Destroy() {
+	if fb.renderer != nil {
+		fb.renderer.removePass(fb)
+	}
 	gl.DeleteFramebuffers(1, &fb.ID)
 	gl.DeleteTextures(1, &fb.TextureID)
 	gl.DeleteRenderbuffers(1, &fb.RenderID)
+	if fb.multisampled() {
+		gl.DeleteFramebuffers(1, &fb.resolveID)
+		gl.DeleteRenderbuffers(1, &fb.colorID)
+	}
 }
 
 func (fb *Framebuffer) Draw(x, y, width, height int) {
@@ -138,19 +241,19 @@ func (fr *FramebufferRenderer) Clear(c color.Color) {
 	fr.framebuffer.Clear(c)
 }
 
+// Draw composites the framebuffer into the target it was bound from.
+// Its own pass is drawn first when the frame is rendered.
 func (fr *FramebufferRenderer) Draw(x, y, width, height int) {
-	fr.Bind()
-	fr.Renderer.Draw()
-	fr.Unbind()
-
-	gl.Viewport(int32(x), int32(y), int32(width), int32(height))
-
-	fr.Renderer.RenderFramebuffer(fr.framebuffer, &graphics.TextureRenderOptions{
-		X:             float32(x),
-		Y:             float32(y),
-		Width:         float32(width),
-		Height:        float32(height),
-		DesiredWidth:  float32(width),
-		DesiredHeight: float32(height),
+	fr.Renderer.inParentPass(fr.framebuffer, func() {
+		fr.Renderer.RenderFramebuffer(fr.framebuffer, &graphics.TextureRenderOptions{
+			X:             float32(x),
+			Y:             float32(y),
+			Width:         float32(fr.framebuffer.Width),
+			Height:        float32(fr.framebuffer.Height),
+			RectWidth:     float32(fr.framebuffer.Width),
+			RectHeight:    float32(fr.framebuffer.Height),
+			DesiredWidth:  float32(width),
+			DesiredHeight: float32(height),
+		})
 	})
 }
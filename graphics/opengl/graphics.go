@@ -1,10 +1,11 @@
 package opengl
 
 import (
-	"log"
-
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/dfirebaugh/banana/graphics/opengl/shaders"
 	"github.com/dfirebaugh/banana/graphics/window"
-	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 type GraphicsBackend struct {
@@ -21,10 +22,27 @@ func NewGraphicsBackend(width, height int) (*GraphicsBackend, error) {
 	gb := &GraphicsBackend{
 		Window: w,
 	}
-	if err := gl.Init(); err != nil {
-		log.Fatalf("Failed to initialize OpenGL bindings: %s\n", err)
+	// the bindings are loaded through GLFW, which knows whether the context came from GLX, WGL or EGL
+	getProcAddr := glfw.GetProcAddress
+	if w.Context.ES {
+		var err error
+		if getProcAddr, err = esProcAddrFunc(glfw.GetProcAddress); err != nil {
+			diag.Errorf("Failed to initialize OpenGL bindings for %s: %v", w.Context, err)
+			return nil, err
+		}
+	}
+	if err := gl.InitWithProcAddrFunc(getProcAddr); err != nil {
+		diag.Errorf("Failed to initialize OpenGL bindings for %s, the driver does not provide %s", w.Context, err)
+		return nil, err
 	}
 	renderer := NewRenderer()
+	if w.Context.ES {
+		renderer.glsl = shaders.GLSLES300
+	}
+	diag.Infof("Created %s context", w.Context)
+	if window.DebugContext {
+		renderer.SetDebugOutput(true)
+	}
 
 	renderer.Init()
 	gb.Renderer = renderer
@@ -0,0 +1,201 @@
+package opengl
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+const (
+	shaderPollInterval = 250 * time.Millisecond
+	// bannerLines is the most lines of compile errors drawn on screen.
+	bannerLines      = 8
+	bannerLineHeight = 14
+)
+
+// shaderWatcher polls shader files for changes while hot reloading is enabled.
+type shaderWatcher struct {
+	// dir holds primitive.vert and primitive.frag overrides of the uber shader. It may be empty.
+	dir      string
+	lastPoll time.Time
+	modTimes map[string]time.Time
+	// errors holds the latest compile error of every shader that is currently broken.
+	errors map[string]error
+}
+
+// EnableShaderHotReload watches shader files and recompiles them when they change.
+// Shaders loaded from files are always watched. When dir is not empty, primitive.vert and
+// primitive.frag in dir replace the uber shader and are watched as well.
+// A shader that fails to compile keeps its last working program and its errors are drawn on screen.
+func (renderer *Renderer) EnableShaderHotReload(dir string) error {
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+	renderer.watcher = &shaderWatcher{
+		dir:      dir,
+		modTimes: make(map[string]time.Time),
+		errors:   make(map[string]error),
+	}
+	// record the files as they are now, except for the uber shader overrides which are loaded on the next frame
+	for _, s := range renderer.fileShaders {
+		renderer.watcher.changed(s.vertexPath)
+		renderer.watcher.changed(s.fragmentPath)
+	}
+	return nil
+}
+
+// changed reports whether a file was modified since it was last checked.
+// Missing files are reported as unchanged, since editors often replace files by removing them first.
+func (w *shaderWatcher) changed(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	last, ok := w.modTimes[path]
+	w.modTimes[path] = info.ModTime()
+	return !ok || !info.ModTime().Equal(last)
+}
+
+func (w *shaderWatcher) report(name string, err error) {
+	if err == nil {
+		if _, ok := w.errors[name]; ok {
+			diag.Infof("Shader %s reloaded", name)
+		}
+		delete(w.errors, name)
+		return
+	}
+	diag.Errorf("Failed to reload shader %s: %v", name, err)
+	w.errors[name] = err
+}
+
+// pollShaders recompiles the watched shaders whose files changed.
+func (renderer *Renderer) pollShaders() {
+	w := renderer.watcher
+	if w == nil || time.Since(w.lastPoll) < shaderPollInterval {
+		return
+	}
+	w.lastPoll = time.Now()
+
+	if w.dir != "" {
+		vertexPath := filepath.Join(w.dir, "primitive.vert")
+		fragmentPath := filepath.Join(w.dir, "primitive.frag")
+		vertexChanged := w.changed(vertexPath)
+		fragmentChanged := w.changed(fragmentPath)
+		if vertexChanged || fragmentChanged {
+			w.report(w.dir, renderer.reloadUberShader(vertexPath, fragmentPath))
+		}
+	}
+
+	for _, s := range renderer.fileShaders {
+		vertexChanged := w.changed(s.vertexPath)
+		fragmentChanged := w.changed(s.fragmentPath)
+		if vertexChanged || fragmentChanged {
+			w.report(s.fragmentPath, s.reload())
+		}
+	}
+}
+
+// reloadUberShader replaces the uber shader with the files that exist in the watched directory.
+func (renderer *Renderer) reloadUberShader(vertexPath, fragmentPath string) error {
+	vertexSource, fragmentBase := renderer.vertexSource, renderer.fragmentBase
+	if source, err := os.ReadFile(vertexPath); err == nil {
+		renderer.vertexSource = string(source)
+	}
+	if source, err := os.ReadFile(fragmentPath); err == nil {
+		renderer.fragmentBase = string(source)
+	}
+	if err := renderer.rebuildProgram(); err != nil {
+		renderer.vertexSource, renderer.fragmentBase = vertexSource, fragmentBase
+		return err
+	}
+	return nil
+}
+
+// NewShaderFromFiles compiles a shader from GLSL files so it can be hot reloaded.
+func (renderer *Renderer) NewShaderFromFiles(vertexPath, fragmentPath string) (graphics.Shader, error) {
+	s, err := NewShaderFromFiles(vertexPath, fragmentPath, renderer.glsl)
+	if err != nil {
+		return nil, err
+	}
+	renderer.fileShaders = append(renderer.fileShaders, s)
+	if renderer.watcher != nil {
+		renderer.watcher.changed(vertexPath)
+		renderer.watcher.changed(fragmentPath)
+	}
+	return s, nil
+}
+
+// queueShaderErrorBanner queues a banner listing the current shader compile errors across the top of the screen.
+func (renderer *Renderer) queueShaderErrorBanner() {
+	names := make([]string, 0, len(renderer.watcher.errors))
+	for name := range renderer.watcher.errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		lines = append(lines, "shader error: "+name)
+		lines = append(lines, strings.Split(renderer.watcher.errors[name].Error(), "\n")...)
+	}
+	if len(lines) > bannerLines {
+		lines = append(lines[:bannerLines-1], "...")
+	}
+
+	width, height := renderer.GetViewportSize()
+	if err := renderer.ensureCapacityForVertices(6); err != nil {
+		return
+	}
+	bottom := 1 - 2*float32((len(lines)+1)*bannerLineHeight)/float32(height)
+	corners := [6][2]float32{{-1, 1}, {1, 1}, {1, bottom}, {-1, 1}, {1, bottom}, {-1, bottom}}
+	for i, corner := range corners {
+		renderer.Vertices[renderer.VertexCount+i] = graphics.Vertex{
+			FsQuadPos:  corner,
+			ShapePos:   corner,
+			OpCode:     graphics.OP_CODE_VERTEX,
+			Color:      [4]float32{0.6, 0, 0, 0.85},
+			Resolution: [2]float32{float32(width), float32(height)},
+		}
+	}
+	renderer.VertexCount += len(corners)
+
+	for i, line := range lines {
+		renderer.RenderText(line, &graphics.TextRenderOptions{
+			X:     6,
+			Y:     float32((i + 1) * bannerLineHeight),
+			Size:  10,
+			Color: color.White,
+		})
+	}
+}
+
+// flushWithShaderErrorBanner draws the queued geometry with the error banner on top.
+// The banner is queued again every frame, so it is dropped from the geometry afterwards.
+func (renderer *Renderer) flushWithShaderErrorBanner() {
+	if renderer.watcher == nil || len(renderer.watcher.errors) == 0 {
+		renderer.flush()
+		return
+	}
+	vertexCount := renderer.VertexCount
+	bindings := append([]textureBinding(nil), renderer.bindings...)
+	renderer.queueShaderErrorBanner()
+	renderer.flush()
+	renderer.VertexCount = vertexCount
+	renderer.bindings = append(renderer.bindings[:0], bindings...)
+}
@@ -0,0 +1,145 @@
+package opengl
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// paletteSize is the number of entries of the palette texture of an indexed texture.
+const paletteSize = 256
+
+// IndexedTexture holds 8-bit palette indices in a single channel texture and the palette in a
+// paletteSize by 1 texture. The shader looks every index up in the palette, so either can change
+// without uploading the other.
+type IndexedTexture struct {
+	ID        uint32
+	PaletteID uint32
+	Width     int
+	Height    int
+}
+
+func newIndexedTexture(img *image.Paletted) *IndexedTexture {
+	bounds := img.Bounds()
+	t := &IndexedTexture{Width: bounds.Dx(), Height: bounds.Dy()}
+
+	gl.GenTextures(1, &t.ID)
+	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R8, int32(t.Width), int32(t.Height), 0, gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(flipIndicesVertically(img)))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	setNearestClamped()
+
+	gl.GenTextures(1, &t.PaletteID)
+	gl.BindTexture(gl.TEXTURE_2D, t.PaletteID)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, paletteSize, 1, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(paletteBytes(img.Palette)))
+	setNearestClamped()
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return t
+}
+
+// setNearestClamped samples the bound texture without filtering, as indices can not be interpolated.
+func setNearestClamped() {
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+}
+
+// updatePixels replaces part of the indices. xOffset and yOffset are measured from the top-left of the texture.
+func (t *IndexedTexture) updatePixels(img *image.Paletted, xOffset, yOffset int) {
+	bounds := img.Bounds()
+	glY := t.Height - yOffset - bounds.Dy()
+
+	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(xOffset), int32(glY), int32(bounds.Dx()), int32(bounds.Dy()), gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(flipIndicesVertically(img)))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+func (t *IndexedTexture) updatePalette(palette color.Palette) {
+	gl.BindTexture(gl.TEXTURE_2D, t.PaletteID)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, paletteSize, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(paletteBytes(palette)))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+func (t *IndexedTexture) Destroy() {
+	gl.DeleteTextures(1, &t.ID)
+	gl.DeleteTextures(1, &t.PaletteID)
+}
+
+// flipIndicesVertically returns the rows of img bottom first, tightly packed.
+func flipIndicesVertically(img *image.Paletted) []uint8 {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	pix := make([]uint8, width*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		from := img.PixOffset(bounds.Min.X, y)
+		to := (bounds.Max.Y - 1 - y) * width
+		copy(pix[to:to+width], img.Pix[from:from+width])
+	}
+	return pix
+}
+
+// paletteBytes returns the premultiplied RGBA bytes of paletteSize entries. Missing entries are transparent.
+func paletteBytes(palette color.Palette) []uint8 {
+	pix := make([]uint8, paletteSize*4)
+	for i, c := range palette[:min(len(palette), paletteSize)] {
+		r, g, b, a := c.RGBA()
+		pix[i*4+0] = uint8(r >> 8)
+		pix[i*4+1] = uint8(g >> 8)
+		pix[i*4+2] = uint8(b >> 8)
+		pix[i*4+3] = uint8(a >> 8)
+	}
+	return pix
+}
+
+// UploadIndexedTexture uploads the indices and palette of img into a texture of their own.
+func (tm *TextureManager) UploadIndexedTexture(img *image.Paletted) uint32 {
+	bounds := img.Bounds()
+	t := newIndexedTexture(img)
+
+	textureID := tm.newTextureID()
+	tm.textureBounds[textureID] = image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+	tm.indexed[textureID] = t
+
+	diag.Debugf("Uploaded indexed texture with ID %d (%dx%d)", textureID, bounds.Dx(), bounds.Dy())
+	return textureID
+}
+
+// UpdateIndexedTexture replaces part of the indices of an indexed texture. The palette is left alone.
+func (tm *TextureManager) UpdateIndexedTexture(textureID uint32, img *image.Paletted, xOffset, yOffset int) {
+	t, ok := tm.indexed[textureID]
+	if !ok {
+		diag.Errorf("Indexed texture ID %d not found", textureID)
+		return
+	}
+	t.updatePixels(img, xOffset, yOffset)
+}
+
+// UpdatePalette replaces the palette of an indexed texture without uploading its indices.
+func (tm *TextureManager) UpdatePalette(textureID uint32, palette color.Palette) {
+	t, ok := tm.indexed[textureID]
+	if !ok {
+		diag.Errorf("Indexed texture ID %d not found", textureID)
+		return
+	}
+	t.updatePalette(palette)
+}
+
+// RenderIndexedTexture draws an indexed texture, looking its pixels up in its palette.
+func (renderer *Renderer) RenderIndexedTexture(textureID uint32, options *graphics.TextureRenderOptions) {
+	t, ok := renderer.TextureManager.indexed[textureID]
+	if !ok {
+		diag.Errorf("Indexed texture handle not found")
+		return
+	}
+	options.Width = float32(t.Width)
+	options.Height = float32(t.Height)
+	renderer.useIndexedTexture(t.ID, t.PaletteID)
+	renderer.renderTextureQuad(options, graphics.OP_CODE_TEXTURE_INDEXED)
+}
@@ -0,0 +1,79 @@
+package opengl
+
+import (
+	"image"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// RenderNineSlice draws a texture stretched to the destination rectangle.
+// The corners keep their size, the top and bottom edges stretch horizontally,
+// the left and right edges stretch vertically and the center stretches both ways.
+func (renderer *Renderer) RenderNineSlice(textureID uint32, insets graphics.Insets, options *graphics.NineSliceRenderOptions) {
+	region, exists := renderer.TextureManager.region(textureID)
+	if !exists {
+		diag.Errorf("Texture handle not found")
+		return
+	}
+
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	bounds := region.bounds
+	renderer.useTexture(region.glTextureID)
+	srcWidth := float32(bounds.Dx())
+	srcHeight := float32(bounds.Dy())
+	srcCols := [4]float32{0, insets.Left, srcWidth - insets.Right, srcWidth}
+	srcRows := [4]float32{0, insets.Top, srcHeight - insets.Bottom, srcHeight}
+
+	left, right := fitInsets(insets.Left*scale, insets.Right*scale, options.Width)
+	top, bottom := fitInsets(insets.Top*scale, insets.Bottom*scale, options.Height)
+	dstCols := [4]float32{0, left, options.Width - right, options.Width}
+	dstRows := [4]float32{0, top, options.Height - bottom, options.Height}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			srcW := srcCols[col+1] - srcCols[col]
+			srcH := srcRows[row+1] - srcRows[row]
+			dstW := dstCols[col+1] - dstCols[col]
+			dstH := dstRows[row+1] - dstRows[row]
+			if srcW <= 0 || srcH <= 0 || dstW <= 0 || dstH <= 0 {
+				continue
+			}
+
+			rectX, rectY := atlasSubRect(bounds, srcCols[col], srcRows[row], srcW, srcH)
+			renderer.renderTexture(&graphics.TextureRenderOptions{
+				X:             options.X + dstCols[col],
+				Y:             options.Y + dstRows[row],
+				RectX:         rectX,
+				RectY:         rectY,
+				RectWidth:     srcW,
+				RectHeight:    srcH,
+				DesiredWidth:  dstW,
+				DesiredHeight: dstH,
+				Width:         float32(region.width),
+				Height:        float32(region.height),
+				Tint:          options.Tint,
+				Alpha:         options.Alpha,
+			})
+		}
+	}
+}
+
+// atlasSubRect converts a sub-rectangle given from the top-left of a texture
+// into atlas coordinates. Textures are stored flipped vertically in the atlas.
+func atlasSubRect(bounds image.Rectangle, x, y, width, height float32) (float32, float32) {
+	return float32(bounds.Min.X) + x, float32(bounds.Max.Y) - y - height
+}
+
+// fitInsets shrinks a pair of insets proportionally when they do not fit in the available length.
+func fitInsets(a, b, length float32) (float32, float32) {
+	if a+b <= length || a+b == 0 {
+		return a, b
+	}
+	ratio := length / (a + b)
+	return a * ratio, b * ratio
+}
@@ -0,0 +1,176 @@
+package opengl
+
+import (
+	"image"
+	"image/draw"
+)
+
+// atlasPadding is the border kept around every texture in the atlas.
+// The border is filled with the texture's edge pixels so filtering never bleeds in a neighbor.
+const atlasPadding = 1
+
+// maxRectsPacker tracks the free space of an atlas as a list of maximal free rectangles
+// and places new rectangles with the best short side fit heuristic.
+type maxRectsPacker struct {
+	width, height int
+	freeRects     []image.Rectangle
+}
+
+func newMaxRectsPacker(width, height int) *maxRectsPacker {
+	return &maxRectsPacker{
+		width:     width,
+		height:    height,
+		freeRects: []image.Rectangle{image.Rect(0, 0, width, height)},
+	}
+}
+
+// Insert reserves a width by height rectangle and reports false when it does not fit.
+func (p *maxRectsPacker) Insert(width, height int) (image.Rectangle, bool) {
+	bestShortSide, bestLongSide := -1, -1
+	var best image.Rectangle
+
+	for _, free := range p.freeRects {
+		if width > free.Dx() || height > free.Dy() {
+			continue
+		}
+		leftoverX := free.Dx() - width
+		leftoverY := free.Dy() - height
+		shortSide, longSide := min(leftoverX, leftoverY), max(leftoverX, leftoverY)
+		if bestShortSide == -1 || shortSide < bestShortSide || (shortSide == bestShortSide && longSide < bestLongSide) {
+			best = image.Rect(free.Min.X, free.Min.Y, free.Min.X+width, free.Min.Y+height)
+			bestShortSide, bestLongSide = shortSide, longSide
+		}
+	}
+
+	if bestShortSide == -1 {
+		return image.Rectangle{}, false
+	}
+
+	p.place(best)
+	return best, true
+}
+
+// place removes a rectangle from the free space.
+func (p *maxRectsPacker) place(used image.Rectangle) {
+	var next []image.Rectangle
+	for _, free := range p.freeRects {
+		if !free.Overlaps(used) {
+			next = append(next, free)
+			continue
+		}
+		if used.Min.X > free.Min.X {
+			next = append(next, image.Rect(free.Min.X, free.Min.Y, used.Min.X, free.Max.Y))
+		}
+		if used.Max.X < free.Max.X {
+			next = append(next, image.Rect(used.Max.X, free.Min.Y, free.Max.X, free.Max.Y))
+		}
+		if used.Min.Y > free.Min.Y {
+			next = append(next, image.Rect(free.Min.X, free.Min.Y, free.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < free.Max.Y {
+			next = append(next, image.Rect(free.Min.X, used.Max.Y, free.Max.X, free.Max.Y))
+		}
+	}
+	p.freeRects = next
+	p.prune()
+}
+
+// Free returns a rectangle that was handed out by Insert to the free space.
+func (p *maxRectsPacker) Free(rect image.Rectangle) {
+	p.freeRects = append(p.freeRects, rect)
+	p.mergeFree()
+	p.prune()
+}
+
+// mergeFree joins free rectangles that share a full edge, so freed space can hold larger rectangles again.
+func (p *maxRectsPacker) mergeFree() {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(p.freeRects) && !merged; i++ {
+			for j := i + 1; j < len(p.freeRects); j++ {
+				a, b := p.freeRects[i], p.freeRects[j]
+				sameColumn := a.Min.X == b.Min.X && a.Max.X == b.Max.X && (a.Max.Y == b.Min.Y || b.Max.Y == a.Min.Y)
+				sameRow := a.Min.Y == b.Min.Y && a.Max.Y == b.Max.Y && (a.Max.X == b.Min.X || b.Max.X == a.Min.X)
+				if sameColumn || sameRow {
+					p.freeRects[i] = a.Union(b)
+					p.freeRects = append(p.freeRects[:j], p.freeRects[j+1:]...)
+					merged = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// prune drops free rectangles that are fully contained in another free rectangle.
+func (p *maxRectsPacker) prune() {
+	for i := 0; i < len(p.freeRects); i++ {
+		for j := i + 1; j < len(p.freeRects); j++ {
+			if p.freeRects[i].In(p.freeRects[j]) {
+				p.freeRects = append(p.freeRects[:i], p.freeRects[i+1:]...)
+				i--
+				break
+			}
+			if p.freeRects[j].In(p.freeRects[i]) {
+				p.freeRects = append(p.freeRects[:j], p.freeRects[j+1:]...)
+				j--
+			}
+		}
+	}
+}
+
+// Grow extends the packing area. Space that was already allocated stays where it is.
+func (p *maxRectsPacker) Grow(width, height int) {
+	if width > p.width {
+		p.freeRects = append(p.freeRects, image.Rect(p.width, 0, width, max(height, p.height)))
+	}
+	if height > p.height {
+		p.freeRects = append(p.freeRects, image.Rect(0, p.height, max(width, p.width), height))
+	}
+	p.width = max(width, p.width)
+	p.height = max(height, p.height)
+	p.prune()
+}
+
+// drawExtruded draws src into dst at rect and repeats its outermost pixels into the padding around rect.
+func drawExtruded(dst *image.RGBA, rect image.Rectangle, src image.Image, padding int) {
+	draw.Draw(dst, rect, src, src.Bounds().Min, draw.Src)
+	extrudeEdges(dst, rect, padding)
+}
+
+// extrudeEdges repeats the outermost pixels of rect into the padding around it.
+func extrudeEdges(dst *image.RGBA, rect image.Rectangle, padding int) {
+	if padding == 0 || rect.Empty() {
+		return
+	}
+
+	for i := 1; i <= padding; i++ {
+		// left and right columns
+		copyColumn(dst, rect.Min.X, rect.Min.X-i, rect.Min.Y, rect.Max.Y)
+		copyColumn(dst, rect.Max.X-1, rect.Max.X-1+i, rect.Min.Y, rect.Max.Y)
+	}
+	for i := 1; i <= padding; i++ {
+		// top and bottom rows, including the corners filled by the columns above
+		copyRow(dst, rect.Min.Y, rect.Min.Y-i, rect.Min.X-padding, rect.Max.X+padding)
+		copyRow(dst, rect.Max.Y-1, rect.Max.Y-1+i, rect.Min.X-padding, rect.Max.X+padding)
+	}
+}
+
+func copyColumn(img *image.RGBA, fromX, toX, minY, maxY int) {
+	if !image.Pt(toX, minY).In(img.Rect) {
+		return
+	}
+	for y := minY; y < maxY; y++ {
+		img.SetRGBA(toX, y, img.RGBAAt(fromX, y))
+	}
+}
+
+func copyRow(img *image.RGBA, fromY, toY, minX, maxX int) {
+	if !image.Pt(minX, toY).In(img.Rect) && !image.Pt(maxX-1, toY).In(img.Rect) {
+		return
+	}
+	start := img.PixOffset(max(minX, img.Rect.Min.X), fromY)
+	end := img.PixOffset(min(maxX, img.Rect.Max.X), fromY)
+	dst := img.PixOffset(max(minX, img.Rect.Min.X), toY)
+	copy(img.Pix[dst:dst+end-start], img.Pix[start:end])
+}
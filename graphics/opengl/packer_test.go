@@ -0,0 +1,136 @@
+package opengl
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// checkPacking fails when allocations overlap each other or the free space, or leave the packing area.
+func checkPacking(t *testing.T, p *maxRectsPacker, used []image.Rectangle) {
+	t.Helper()
+	bounds := image.Rect(0, 0, p.width, p.height)
+	for i, a := range used {
+		if !a.In(bounds) {
+			t.Errorf("allocation %v lies outside of %v", a, bounds)
+		}
+		for _, b := range used[i+1:] {
+			if a.Overlaps(b) {
+				t.Errorf("allocations %v and %v overlap", a, b)
+			}
+		}
+		for _, free := range p.freeRects {
+			if a.Overlaps(free) {
+				t.Errorf("allocation %v overlaps free space %v", a, free)
+			}
+		}
+	}
+	for _, free := range p.freeRects {
+		if !free.In(bounds) {
+			t.Errorf("free space %v lies outside of %v", free, bounds)
+		}
+	}
+}
+
+func fill(p *maxRectsPacker, width, height int) []image.Rectangle {
+	var used []image.Rectangle
+	for {
+		r, ok := p.Insert(width, height)
+		if !ok {
+			return used
+		}
+		used = append(used, r)
+	}
+}
+
+func TestPackerFillsTheArea(t *testing.T) {
+	p := newMaxRectsPacker(64, 64)
+	used := fill(p, 16, 16)
+	if len(used) != 16 {
+		t.Errorf("fit %d 16x16 rects in 64x64, want 16", len(used))
+	}
+	if len(p.freeRects) != 0 {
+		t.Errorf("a full packer has free space %v", p.freeRects)
+	}
+	checkPacking(t, p, used)
+}
+
+func TestPackerReusesFreedSpace(t *testing.T) {
+	p := newMaxRectsPacker(64, 64)
+	used := fill(p, 16, 16)
+
+	p.Free(used[5])
+	r, ok := p.Insert(16, 16)
+	if !ok || r != used[5] {
+		t.Fatalf("Insert after Free = %v, %v, want %v", r, ok, used[5])
+	}
+
+	// freeing a 2x2 block of neighbors makes room for a rectangle that covers all of them
+	var block, rest []image.Rectangle
+	corner := image.Rect(0, 0, 32, 32)
+	for _, u := range used {
+		if u.In(corner) {
+			block = append(block, u)
+		} else {
+			rest = append(rest, u)
+		}
+	}
+	for _, u := range block {
+		p.Free(u)
+	}
+	if _, ok := p.Insert(33, 32); ok {
+		t.Error("a 33x32 rect fit in a 32x32 hole")
+	}
+	r, ok = p.Insert(32, 32)
+	if !ok || r != corner {
+		t.Fatalf("Insert(32, 32) = %v, %v, want %v", r, ok, corner)
+	}
+	checkPacking(t, p, append(rest, r))
+}
+
+func TestPackerRandomAllocations(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	p := newMaxRectsPacker(128, 128)
+	var used []image.Rectangle
+	for i := 0; i < 500; i++ {
+		if i == 250 {
+			p.Grow(256, 192)
+		}
+		if len(used) > 0 && rng.Intn(3) == 0 {
+			n := rng.Intn(len(used))
+			p.Free(used[n])
+			used = append(used[:n], used[n+1:]...)
+			continue
+		}
+		if r, ok := p.Insert(1+rng.Intn(40), 1+rng.Intn(40)); ok {
+			used = append(used, r)
+		}
+	}
+	checkPacking(t, p, used)
+}
+
+func TestPackerGrow(t *testing.T) {
+	p := newMaxRectsPacker(32, 32)
+	used := fill(p, 16, 16)
+	first := append([]image.Rectangle(nil), used...)
+
+	p.Grow(64, 48)
+	used = append(used, fill(p, 16, 16)...)
+	if len(used) != 12 {
+		t.Errorf("fit %d 16x16 rects in 64x48, want 12", len(used))
+	}
+	for i, r := range first {
+		if used[i] != r {
+			t.Errorf("growing moved %v to %v", r, used[i])
+		}
+	}
+	checkPacking(t, p, used)
+
+	// growing in one direction only
+	p.Grow(64, 64)
+	used = append(used, fill(p, 64, 16)...)
+	if len(used) != 13 {
+		t.Errorf("a 64x16 strip did not fit below the grown area")
+	}
+	checkPacking(t, p, used)
+}
@@ -0,0 +1,197 @@
+package opengl
+
+import (
+	"image"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// renderPass collects everything drawn into one target during a frame.
+// Geometry is only sent to the GPU when the frame is drawn, so binding a framebuffer
+// in the middle of a frame never flushes or mixes in geometry meant for another target.
+type renderPass struct {
+	// framebuffer is the target of the pass, or nil for the screen.
+	framebuffer *Framebuffer
+	vertices    []graphics.Vertex
+	vertexCount int
+	bindings    []textureBinding
+	// clearColor is set when the target is cleared before the geometry is drawn.
+	clearColor *[4]float32
+	viewport   [4]int32
+	// dependencies are the framebuffers sampled by this pass. Their passes are drawn first.
+	dependencies map[*Framebuffer]bool
+}
+
+func newRenderPass(fb *Framebuffer) *renderPass {
+	return &renderPass{
+		framebuffer:  fb,
+		dependencies: make(map[*Framebuffer]bool),
+	}
+}
+
+// pending reports whether the pass has anything to draw.
+func (pass *renderPass) pending() bool {
+	return pass.vertexCount > 0 || pass.clearColor != nil
+}
+
+// reset drops the recorded geometry once a framebuffer pass has been drawn.
+// The framebuffer keeps its contents, so it can be sampled on later frames without redrawing it.
+func (pass *renderPass) reset() {
+	pass.vertexCount = 0
+	pass.vertices = pass.vertices[:0]
+	pass.bindings = pass.bindings[:0]
+	pass.clearColor = nil
+	clear(pass.dependencies)
+}
+
+// storePass moves the geometry queued on the renderer into a pass.
+func (renderer *Renderer) storePass(pass *renderPass) {
+	pass.vertices = renderer.Vertices
+	pass.vertexCount = renderer.VertexCount
+	pass.bindings = renderer.bindings
+}
+
+// loadPass makes the renderer queue geometry into a pass.
+func (renderer *Renderer) loadPass(pass *renderPass) {
+	renderer.Vertices = pass.vertices
+	renderer.VertexCount = pass.vertexCount
+	renderer.bindings = pass.bindings
+}
+
+// passFor returns the pass that draws into a framebuffer.
+func (renderer *Renderer) passFor(fb *Framebuffer) *renderPass {
+	pass, ok := renderer.passes[fb]
+	if !ok {
+		pass = newRenderPass(fb)
+		renderer.passes[fb] = pass
+	}
+	return pass
+}
+
+// switchPass stores the current pass and starts recording into another one.
+// The viewport is switched as well, since geometry is laid out against the viewport of its target.
+func (renderer *Renderer) switchPass(pass *renderPass) {
+	if renderer.current == renderer.screenPass {
+		renderer.screenPass.viewport = renderer.getViewport()
+	}
+	renderer.storePass(renderer.current)
+	renderer.current = pass
+	renderer.loadPass(pass)
+	renderer.bindTarget(pass)
+}
+
+// bindTarget binds the target of a pass and its viewport.
+func (renderer *Renderer) bindTarget(pass *renderPass) {
+	if pass.framebuffer == nil {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+		gl.Viewport(pass.viewport[0], pass.viewport[1], pass.viewport[2], pass.viewport[3])
+		return
+	}
+	pass.viewport = [4]int32{0, 0, int32(pass.framebuffer.Width), int32(pass.framebuffer.Height)}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pass.framebuffer.ID)
+	gl.Viewport(0, 0, pass.viewport[2], pass.viewport[3])
+}
+
+func (renderer *Renderer) getViewport() [4]int32 {
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	return viewport
+}
+
+// inParentPass runs fn while recording into the pass a framebuffer was bound from,
+// so the framebuffer can be composited into it.
+func (renderer *Renderer) inParentPass(fb *Framebuffer, fn func()) {
+	if renderer.current.framebuffer != fb {
+		fn()
+		return
+	}
+	parent := renderer.screenPass
+	if n := len(renderer.passStack); n > 0 {
+		parent = renderer.passStack[n-1]
+	}
+	current := renderer.current
+	renderer.switchPass(parent)
+	fn()
+	renderer.switchPass(current)
+}
+
+// passOrder returns the passes that need drawing with every framebuffer pass ahead of the passes sampling it.
+// The screen is always drawn last.
+func (renderer *Renderer) passOrder() []*renderPass {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[*renderPass]int)
+	var order []*renderPass
+
+	var visit func(pass *renderPass)
+	visit = func(pass *renderPass) {
+		switch state[pass] {
+		case visiting:
+			diag.Warnf("Framebuffers sample each other in a cycle; the cycle is broken using last frame's contents")
+			return
+		case done:
+			return
+		}
+		state[pass] = visiting
+		for fb := range pass.dependencies {
+			if dependency, ok := renderer.passes[fb]; ok && dependency != pass {
+				visit(dependency)
+			}
+		}
+		state[pass] = done
+		if pass.framebuffer != nil && pass.pending() {
+			order = append(order, pass)
+		}
+	}
+
+	visit(renderer.screenPass)
+	for _, pass := range renderer.passes {
+		visit(pass)
+	}
+	return append(order, renderer.screenPass)
+}
+
+// removePass forgets the pass of a framebuffer that is being destroyed.
+func (renderer *Renderer) removePass(fb *Framebuffer) {
+	pass, ok := renderer.passes[fb]
+	if !ok {
+		return
+	}
+	if renderer.current == pass {
+		renderer.UnbindFramebuffer()
+	}
+	for i, stacked := range renderer.passStack {
+		if stacked == pass {
+			renderer.passStack = append(renderer.passStack[:i], renderer.passStack[i+1:]...)
+			break
+		}
+	}
+	delete(renderer.passes, fb)
+	delete(renderer.screenPass.dependencies, fb)
+	for _, other := range renderer.passes {
+		delete(other.dependencies, fb)
+	}
+}
+
+// ReadScreen reads the screen back from the GPU. Between Draw and swapping the buffers
+// the back buffer holds the finished frame.
+func (renderer *Renderer) ReadScreen() *image.RGBA {
+	viewport := renderer.screenPass.viewport
+	img := image.NewRGBA(image.Rect(0, 0, int(viewport[2]), int(viewport[3])))
+	if img.Rect.Empty() {
+		return img
+	}
+
+	var previous int32
+	gl.GetIntegerv(gl.READ_FRAMEBUFFER_BINDING, &previous)
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.ReadPixels(viewport[0], viewport[1], viewport[2], viewport[3], gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(previous))
+	checkGLErrors("reading the screen")
+	return flipImageVertically(img)
+}
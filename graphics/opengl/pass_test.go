@@ -0,0 +1,51 @@
+package opengl
+
+import (
+	"image/color"
+	"testing"
+)
+
+// newTestFramebuffer returns a framebuffer that records passes on renderer without creating GL objects.
+func newTestFramebuffer(renderer *Renderer) *Framebuffer {
+	fb := &Framebuffer{Width: 16, Height: 16}
+	fb.renderer = &FramebufferRenderer{Renderer: renderer, framebuffer: fb}
+	return fb
+}
+
+func TestFramebufferClearIsRecordedInItsPass(t *testing.T) {
+	renderer := NewRenderer()
+	fb := newTestFramebuffer(renderer)
+	pass := renderer.passFor(fb)
+	pass.vertexCount = 6
+
+	fb.Clear(color.RGBA{255, 0, 0, 255})
+	if pass.clearColor == nil || *pass.clearColor != [4]float32{1, 0, 0, 1} {
+		t.Errorf("pass clear color = %v, want red", pass.clearColor)
+	}
+	if pass.vertexCount != 0 {
+		t.Errorf("clearing kept %d queued vertices", pass.vertexCount)
+	}
+	if renderer.screenPass.clearColor != nil {
+		t.Error("clearing a framebuffer cleared the screen")
+	}
+}
+
+func TestDependenciesAreDropped(t *testing.T) {
+	renderer := NewRenderer()
+	fb := newTestFramebuffer(renderer)
+	other := newTestFramebuffer(renderer)
+
+	renderer.screenPass.dependencies[fb] = true
+	renderer.Clear(color.Black)
+	if len(renderer.screenPass.dependencies) != 0 {
+		t.Errorf("screen still depends on %d framebuffers after a clear", len(renderer.screenPass.dependencies))
+	}
+
+	renderer.passFor(fb)
+	renderer.screenPass.dependencies[fb] = true
+	renderer.passFor(other).dependencies[fb] = true
+	renderer.removePass(fb)
+	if renderer.screenPass.dependencies[fb] || renderer.passes[other].dependencies[fb] {
+		t.Error("a destroyed framebuffer is still a dependency")
+	}
+}
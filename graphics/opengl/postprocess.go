@@ -0,0 +1,143 @@
+package opengl
+
+import (
+	"time"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// postProcessChain renders the screen pass offscreen and runs it through a list of full-screen shaders.
+// Every shader samples the output of the previous one from u_texture, and the last one draws to the window.
+type postProcessChain struct {
+	shaders []*Shader
+	// scene receives the screen pass. ping and pong take turns as the target of the shaders in between.
+	scene, ping, pong *Framebuffer
+	// vao is empty. The full-screen triangle is generated from gl_VertexID.
+	vao   uint32
+	start time.Time
+}
+
+// NewShader compiles a shader from GLSL source. An empty vertex source uses a full-screen vertex shader.
+func (renderer *Renderer) NewShader(vertexSource, fragmentSource string) (graphics.Shader, error) {
+	return NewShader(vertexSource, fragmentSource, renderer.glsl)
+}
+
+// SetPostProcess sets the shaders the frame is run through before it reaches the window.
+// An empty list turns post processing off.
+func (renderer *Renderer) SetPostProcess(shaderList []graphics.Shader) {
+	if len(shaderList) == 0 {
+		if renderer.postProcess != nil {
+			renderer.postProcess.destroy()
+			renderer.postProcess = nil
+		}
+		return
+	}
+
+	list := make([]*Shader, 0, len(shaderList))
+	for _, s := range shaderList {
+		shader, ok := s.(*Shader)
+		if !ok {
+			diag.Errorf("Can not post process with shader of type %T", s)
+			return
+		}
+		if shader.location("u_texture") == -1 {
+			diag.Warnf("Post processing shader %d does not read u_texture, so everything drawn before it is discarded", len(list))
+		}
+		list = append(list, shader)
+	}
+
+	if renderer.postProcess == nil {
+		renderer.postProcess = &postProcessChain{start: time.Now()}
+		gl.GenVertexArrays(1, &renderer.postProcess.vao)
+	}
+	renderer.postProcess.shaders = list
+}
+
+// ensureTarget creates or resizes a framebuffer the chain draws into.
+func (renderer *Renderer) ensureTarget(fb **Framebuffer, width, height int) {
+	if *fb == nil {
+		target, err := NewFramebuffer(width, height, renderer.TextureManager, renderer)
+		if err != nil {
+			diag.Errorf("Failed to create post processing framebuffer: %v", err)
+			return
+		}
+		*fb = target
+		return
+	}
+	if (*fb).Width != width || (*fb).Height != height {
+		(*fb).Resize(width, height)
+	}
+}
+
+// bindPostProcessScene binds the framebuffer the screen pass is drawn into while post processing.
+func (renderer *Renderer) bindPostProcessScene(viewport [4]int32) bool {
+	chain := renderer.postProcess
+	width, height := int(viewport[2]), int(viewport[3])
+	renderer.ensureTarget(&chain.scene, width, height)
+	if len(chain.shaders) > 1 {
+		renderer.ensureTarget(&chain.ping, width, height)
+	}
+	if len(chain.shaders) > 2 {
+		renderer.ensureTarget(&chain.pong, width, height)
+	}
+	if chain.scene == nil {
+		return false
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, chain.scene.ID)
+	gl.Viewport(0, 0, viewport[2], viewport[3])
+	return true
+}
+
+// applyPostProcess runs the scene through every shader, drawing the last one into the window's viewport.
+func (renderer *Renderer) applyPostProcess(viewport [4]int32) {
+	chain := renderer.postProcess
+	elapsed := float32(time.Since(chain.start).Seconds())
+	targets := [2]*Framebuffer{chain.ping, chain.pong}
+
+	gl.Disable(gl.BLEND)
+	gl.BindVertexArray(chain.vao)
+
+	source := chain.scene
+	for i, shader := range chain.shaders {
+		last := i == len(chain.shaders)-1
+		target := targets[i%2]
+		if last {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, target.ID)
+			gl.Viewport(0, 0, int32(target.Width), int32(target.Height))
+		}
+
+		shader.use()
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.BindTexture(gl.TEXTURE_2D, source.TextureID)
+		if location := shader.location("u_texture"); location != -1 {
+			gl.Uniform1i(location, 0)
+		}
+		if location := shader.location("u_resolution"); location != -1 {
+			gl.Uniform2f(location, float32(viewport[2]), float32(viewport[3]))
+		}
+		if location := shader.location("u_time"); location != -1 {
+			gl.Uniform1f(location, elapsed)
+		}
+		gl.DrawArrays(gl.TRIANGLES, 0, 3)
+
+		source = target
+	}
+
+	gl.BindVertexArray(0)
+	gl.Enable(gl.BLEND)
+}
+
+func (chain *postProcessChain) destroy() {
+	for _, fb := range []*Framebuffer{chain.scene, chain.ping, chain.pong} {
+		if fb != nil {
+			fb.Destroy()
+		}
+	}
+	gl.DeleteVertexArrays(1, &chain.vao)
+}
@@ -0,0 +1,112 @@
+package opengl
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+
+static void bananaMissingGLFunction(void) {
+	fprintf(stderr, "banana: called an OpenGL function that the OpenGL ES driver does not provide\n");
+	abort();
+}
+
+static void *bananaMissingGLFunctionAddr(void) {
+	return (void *)bananaMissingGLFunction;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// esFunctions are the entry points the renderer calls. OpenGL ES 3.0 provides all of them.
+// Keep it in sync when the renderer starts using another function.
+var esFunctions = []string{
+	"glActiveTexture",
+	"glAttachShader",
+	"glBindBuffer",
+	"glBindFramebuffer",
+	"glBindRenderbuffer",
+	"glBindTexture",
+	"glBindVertexArray",
+	"glBlendFunc",
+	"glBlitFramebuffer",
+	"glBufferData",
+	"glBufferSubData",
+	"glCheckFramebufferStatus",
+	"glClear",
+	"glClearColor",
+	"glCompileShader",
+	"glCreateProgram",
+	"glCreateShader",
+	"glDeleteBuffers",
+	"glDeleteFramebuffers",
+	"glDeleteProgram",
+	"glDeleteRenderbuffers",
+	"glDeleteShader",
+	"glDeleteTextures",
+	"glDeleteVertexArrays",
+	"glDisable",
+	"glDrawArrays",
+	"glEnable",
+	"glEnableVertexAttribArray",
+	"glFramebufferRenderbuffer",
+	"glFramebufferTexture2D",
+	"glGenBuffers",
+	"glGenFramebuffers",
+	"glGenRenderbuffers",
+	"glGenTextures",
+	"glGenVertexArrays",
+	"glGenerateMipmap",
+	"glGetError",
+	"glGetIntegerv",
+	"glGetProgramInfoLog",
+	"glGetProgramiv",
+	"glGetShaderInfoLog",
+	"glGetShaderiv",
+	"glGetUniformLocation",
+	"glLinkProgram",
+	"glPixelStorei",
+	"glReadPixels",
+	"glRenderbufferStorage",
+	"glRenderbufferStorageMultisample",
+	"glShaderSource",
+	"glTexImage2D",
+	"glTexParameteri",
+	"glTexSubImage2D",
+	"glUniform1f",
+	"glUniform1i",
+	"glUniform2f",
+	"glUniform3f",
+	"glUniform4f",
+	"glUniformMatrix4fv",
+	"glUseProgram",
+	"glVertexAttribPointer",
+	"glViewport",
+}
+
+// esProcAddrFunc resolves entry points for an OpenGL ES context.
+// The bindings are generated for OpenGL 3.3 core and refuse to load unless every desktop function resolves,
+// which vendor ES drivers do not do. Instead the functions the renderer calls are required to resolve and
+// the desktop only ones that do not are pointed at a stub that aborts with a message if it is ever reached.
+func esProcAddrFunc(getProcAddr func(name string) unsafe.Pointer) (func(name string) unsafe.Pointer, error) {
+	var missing []string
+	for _, name := range esFunctions {
+		if getProcAddr(name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the driver does not provide %s", strings.Join(missing, ", "))
+	}
+
+	stub := C.bananaMissingGLFunctionAddr()
+	return func(name string) unsafe.Pointer {
+		if addr := getProcAddr(name); addr != nil {
+			return addr
+		}
+		return unsafe.Pointer(stub)
+	}, nil
+}
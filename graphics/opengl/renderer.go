@@ -1,19 +1,16 @@
 package opengl
 
 import (
-	"fmt"
 	"image/color"
-	"log"
 	"math"
 	"unsafe"
 
 	"github.com/dfirebaugh/banana/assets"
 	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
 	"github.com/dfirebaugh/banana/graphics/font"
 	"github.com/dfirebaugh/banana/graphics/opengl/shaders"
-	"github.com/go-gl/gl/v4.6-core/gl"
-	"github.com/sirupsen/logrus"
-	"golang.org/x/image/math/fixed"
+	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
 type AttribLocation uint32
@@ -31,6 +28,10 @@ const (
 	ATTRIB_RESOLUTION_LOCATION    AttribLocation = 9
 	ATTRIB_TEXTURE_INDEX_LOCATION AttribLocation = 10
 	ATTRIB_FONT_INDEX_LOCATION    AttribLocation = 11
+	ATTRIB_TEX_RECT_LOCATION      AttribLocation = 12
+	ATTRIB_WRAP_MODE_LOCATION     AttribLocation = 13
+	ATTRIB_STROKE_WIDTH_LOCATION  AttribLocation = 14
+	ATTRIB_STROKE_COLOR_LOCATION  AttribLocation = 15
 )
 
 const (
@@ -38,6 +39,8 @@ const (
 	AtlasHeight = 512
 	MaxTextures = 100
 	MaxVertices = 600000
+	// MaxSamplers is the length of the sampler array in the fragment shader.
+	MaxSamplers = 24
 )
 
 type Renderer struct {
@@ -53,6 +56,31 @@ type Renderer struct {
 	Font           *font.Font
 	FontTextureID  uint32
 	*TextureManager
+	// bindings records which GL texture each run of queued vertices samples from.
+	bindings []textureBinding
+	// maxSlots is the number of samplers a single draw call can use.
+	maxSlots int
+
+	// screenPass draws into the window. Framebuffers each get their own pass.
+	screenPass *renderPass
+	passes     map[*Framebuffer]*renderPass
+	// current is the pass geometry is queued into and passStack holds the passes bound before it.
+	current   *renderPass
+	passStack []*renderPass
+
+	// postProcess is nil unless the screen is post processed.
+	postProcess *postProcessChain
+	// customShapes are spliced into the uber shader in the order they were registered.
+	customShapes []customShape
+	// glsl is the dialect shaders are translated to for the current context.
+	glsl shaders.Target
+	// vertexSource and fragmentBase are the uber shader before custom shapes are spliced in.
+	// They are replaced when the shader is hot reloaded.
+	vertexSource, fragmentBase string
+	// fileShaders are the user shaders that were loaded from files.
+	fileShaders []*Shader
+	// watcher is nil unless shader hot reloading is enabled.
+	watcher *shaderWatcher
 }
 
 func NewRenderer() *Renderer {
@@ -66,26 +94,33 @@ func NewRenderer() *Renderer {
 		BufferCapacity: initialCapacity,
 	}
 	renderer.TextureManager = NewTextureManager(renderer)
+	renderer.vertexSource = shaders.VertexShaderSource
+	renderer.fragmentBase = shaders.FragmentShaderSource
+	renderer.screenPass = newRenderPass(nil)
+	renderer.passes = make(map[*Framebuffer]*renderPass)
+	renderer.current = renderer.screenPass
+	renderer.storePass(renderer.screenPass)
 	return renderer
 }
 
 func (renderer *Renderer) Init() {
 	var err error
-	renderer.ShaderProgram, err = newShaderProgram(shaders.VertexShaderSource, shaders.FragmentShaderSource)
+	renderer.querySamplerSlots()
+	renderer.ShaderProgram, err = renderer.compileUberShader()
 	if err != nil {
-		fmt.Printf("Shader compilation or linking error: %s\n", err)
+		diag.Errorf("Failed to build the uber shader: %v", err)
 		return
 	}
 
+	renderer.initSamplers()
+
 	renderer.Font, err = font.LoadFont(assets.LatoRegular)
 	if err != nil {
-		fmt.Printf("Failed to load font: %s\n", err)
+		diag.Errorf("Failed to load font: %v", err)
 		return
 	}
 
-	fontImg := renderer.Font.Image()
-	fontImg = flipImageVertically(fontImg)
-	renderer.FontTextureID = renderer.TextureManager.UploadTexture(fontImg)
+	renderer.uploadFont()
 
 	gl.Enable(gl.BLEND)
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
@@ -134,8 +169,22 @@ func (renderer *Renderer) Init() {
 	gl.EnableVertexAttribArray(uint32(ATTRIB_FONT_INDEX_LOCATION))
 	gl.VertexAttribPointerWithOffset(uint32(ATTRIB_FONT_INDEX_LOCATION), 1, gl.FLOAT, false, stride, unsafe.Offsetof(graphics.Vertex{}.FontIndex))
 
+	gl.EnableVertexAttribArray(uint32(ATTRIB_TEX_RECT_LOCATION))
+	gl.VertexAttribPointerWithOffset(uint32(ATTRIB_TEX_RECT_LOCATION), 4, gl.FLOAT, false, stride, unsafe.Offsetof(graphics.Vertex{}.TexRect))
+
+	gl.EnableVertexAttribArray(uint32(ATTRIB_WRAP_MODE_LOCATION))
+	gl.VertexAttribPointerWithOffset(uint32(ATTRIB_WRAP_MODE_LOCATION), 1, gl.FLOAT, false, stride, unsafe.Offsetof(graphics.Vertex{}.WrapMode))
+
+	gl.EnableVertexAttribArray(uint32(ATTRIB_STROKE_WIDTH_LOCATION))
+	gl.VertexAttribPointerWithOffset(uint32(ATTRIB_STROKE_WIDTH_LOCATION), 1, gl.FLOAT, false, stride, unsafe.Offsetof(graphics.Vertex{}.StrokeWidth))
+
+	gl.EnableVertexAttribArray(uint32(ATTRIB_STROKE_COLOR_LOCATION))
+	gl.VertexAttribPointerWithOffset(uint32(ATTRIB_STROKE_COLOR_LOCATION), 4, gl.FLOAT, false, stride, unsafe.Offsetof(graphics.Vertex{}.StrokeColor))
+
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 	gl.BindVertexArray(0)
+
+	renderer.validate()
 }
 
 func (renderer *Renderer) ensureCapacityForVertices(additionalVertices int) error {
@@ -156,18 +205,29 @@ func (renderer *Renderer) ensureCapacityForVertices(additionalVertices int) erro
 	copy(newVertices, renderer.Vertices[:renderer.VertexCount])
 	renderer.Vertices = newVertices
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, renderer.VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, newCapacity*int(unsafe.Sizeof(graphics.Vertex{})), nil, gl.DYNAMIC_DRAW)
-	gl.BufferSubData(gl.ARRAY_BUFFER, 0, renderer.VertexCount*int(unsafe.Sizeof(graphics.Vertex{})), unsafe.Pointer(&renderer.Vertices[0]))
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return nil
+}
 
+// ensureBufferCapacity grows the vertex buffer so it can hold the given number of vertices.
+// The buffer is shared by every pass, so it only ever grows. It must be bound.
+func (renderer *Renderer) ensureBufferCapacity(vertexCount int) {
+	if vertexCount <= renderer.BufferCapacity {
+		return
+	}
+	newCapacity := max(renderer.BufferCapacity, 1024)
+	for newCapacity < vertexCount {
+		newCapacity *= 2
+	}
+	gl.BufferData(gl.ARRAY_BUFFER, newCapacity*int(unsafe.Sizeof(graphics.Vertex{})), nil, gl.DYNAMIC_DRAW)
 	renderer.BufferCapacity = newCapacity
-
-	return nil
 }
 
 func (renderer *Renderer) AddFramebuffer(width, height int) (graphics.Framebuffer, error) {
-	fb, err := NewFramebuffer(width, height, renderer.TextureManager, renderer)
+	return renderer.AddFramebufferWithOptions(width, height, graphics.FramebufferOptions{})
+}
+
+func (renderer *Renderer) AddFramebufferWithOptions(width, height int, options graphics.FramebufferOptions) (graphics.Framebuffer, error) {
+	fb, err := NewFramebufferWithOptions(width, height, options, renderer.TextureManager, renderer)
 	if err != nil {
 		return nil, err
 	}
@@ -184,58 +244,120 @@ func (renderer *Renderer) Destroy() {
 		gl.DeleteTextures(1, &renderer.Textures[i].ID)
 	}
 
-	renderer.Font.Destroy()
+	for _, t := range renderer.TextureManager.standalone {
+		t.Destroy()
+	}
+	for _, t := range renderer.TextureManager.indexed {
+		t.Destroy()
+	}
+	for _, page := range renderer.TextureManager.pages {
+		page.Destroy()
+	}
+	if renderer.postProcess != nil {
+		renderer.postProcess.destroy()
+	}
 }
 
+// Clear drops the geometry queued for the bound target and clears the target when the frame is drawn.
 func (renderer *Renderer) Clear(c color.Color) {
 	rgba := toRGBA(c)
-	gl.ClearColor(rgba[0], rgba[1], rgba[2], rgba[3])
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+	renderer.current.clearColor = &rgba
+	clear(renderer.current.dependencies)
 	renderer.VertexCount = 0
 	renderer.Vertices = renderer.Vertices[:0]
+	renderer.bindings = renderer.bindings[:0]
+}
+
+// clearFramebuffer clears a framebuffer whether or not it is the bound target.
+func (renderer *Renderer) clearFramebuffer(fb *Framebuffer, c color.Color) {
+	if renderer.current.framebuffer == fb {
+		renderer.Clear(c)
+		return
+	}
+	rgba := toRGBA(c)
+	pass := renderer.passFor(fb)
+	pass.reset()
+	pass.clearColor = &rgba
 }
 
 func (renderer *Renderer) Begin() {
 	renderer.VertexCount = 0
 	renderer.Vertices = renderer.Vertices[:0]
+	renderer.bindings = renderer.bindings[:0]
 }
 
 func (renderer *Renderer) End() {
 	// cleanup
 }
 
+// Draw renders every pass of the frame. Framebuffers are drawn before the passes that sample them
+// and the screen is drawn last. Geometry queued for the screen is kept until it is cleared,
+// while framebuffer passes are consumed, since the framebuffer keeps what was drawn into it.
 func (renderer *Renderer) Draw() {
+	renderer.pollShaders()
+
+	current := renderer.current
+	if current == renderer.screenPass {
+		renderer.screenPass.viewport = renderer.getViewport()
+	}
+	renderer.storePass(current)
+
+	for _, pass := range renderer.passOrder() {
+		renderer.loadPass(pass)
+		postProcessed := pass == renderer.screenPass && renderer.postProcess != nil &&
+			renderer.bindPostProcessScene(pass.viewport)
+		if !postProcessed {
+			renderer.bindTarget(pass)
+		}
+		if pass.clearColor != nil {
+			c := pass.clearColor
+			gl.ClearColor(c[0], c[1], c[2], c[3])
+			gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+		}
+		if pass == renderer.screenPass {
+			renderer.flushWithShaderErrorBanner()
+		} else {
+			renderer.flush()
+		}
+		if postProcessed {
+			renderer.applyPostProcess(pass.viewport)
+		}
+		renderer.storePass(pass)
+		if pass.framebuffer != nil {
+			pass.framebuffer.resolve()
+			pass.reset()
+		}
+	}
+
+	renderer.loadPass(current)
+	renderer.bindTarget(current)
+}
+
+// flush draws the queued geometry into the bound target.
+func (renderer *Renderer) flush() {
 	gl.UseProgram(renderer.ShaderProgram)
 	width, height := renderer.GetViewportSize()
 	gl.Uniform2f(gl.GetUniformLocation(renderer.ShaderProgram, gl.Str("u_resolution\x00")), float32(width), float32(height))
 
+	batches := renderer.buildBatches()
+
 	gl.BindVertexArray(renderer.VAO)
 	gl.BindBuffer(gl.ARRAY_BUFFER, renderer.VBO)
 
+	renderer.ensureBufferCapacity(renderer.VertexCount)
 	size := renderer.VertexCount * int(unsafe.Sizeof(graphics.Vertex{}))
 	if size > 0 {
 		gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, unsafe.Pointer(&renderer.Vertices[0]))
 	}
 
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, renderer.FontTextureID)
-	gl.Uniform1i(gl.GetUniformLocation(renderer.ShaderProgram, gl.Str("samplers[0]\x00")), 0)
-
-	gl.ActiveTexture(gl.TEXTURE1)
-	gl.BindTexture(gl.TEXTURE_2D, renderer.TextureManager.atlas.ID)
-	gl.Uniform1i(gl.GetUniformLocation(renderer.ShaderProgram, gl.Str("samplers[1]\x00")), 1)
-
-	for _, fb := range renderer.Framebuffers {
-		textureUnit := fb.GetTextureID()
-		gl.ActiveTexture(gl.TEXTURE0 + textureUnit)
-		gl.BindTexture(gl.TEXTURE_2D, fb.TextureID)
-		samplerName := fmt.Sprintf("samplers[%d]\x00", textureUnit)
-		gl.Uniform1i(gl.GetUniformLocation(renderer.ShaderProgram, gl.Str(samplerName)), int32(textureUnit))
-	}
-
-	if renderer.VertexCount > 0 {
-		gl.DrawArrays(gl.TRIANGLES, 0, int32(renderer.VertexCount))
+	for _, batch := range batches {
+		for slot, glTextureID := range batch.textures {
+			gl.ActiveTexture(gl.TEXTURE0 + uint32(slot))
+			gl.BindTexture(gl.TEXTURE_2D, glTextureID)
+		}
+		gl.DrawArrays(gl.TRIANGLES, int32(batch.first), int32(batch.count))
 	}
+	gl.ActiveTexture(gl.TEXTURE0)
 
 	gl.BindVertexArray(0)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
@@ -250,7 +372,7 @@ func (renderer *Renderer) Render(shape graphics.Renderable) {
 	additionalVertices := len(vertices)
 
 	if err := renderer.ensureCapacityForVertices(additionalVertices); err != nil {
-		logrus.Errorf("Failed to ensure capacity: %v", err)
+		diag.Errorf("Failed to ensure capacity: %v", err)
 		return
 	}
 
@@ -258,16 +380,6 @@ func (renderer *Renderer) Render(shape graphics.Renderable) {
 	renderer.VertexCount += additionalVertices
 }
 
-func bruteForceFixFloaters(r rune, ypos float32, ptSize float32) float32 {
-	if r == '^' || r == '\'' {
-		return ypos + ptSize
-	}
-	if r == '\'' || r == '"' || r == '`' {
-		return ypos + ptSize/2
-	}
-	return ypos
-}
-
 func (renderer *Renderer) RenderText(text string, options *graphics.TextRenderOptions) {
 	r, g, b, a := options.Color.RGBA()
 	colorVec := [4]float32{
@@ -277,43 +389,26 @@ func (renderer *Renderer) RenderText(text string, options *graphics.TextRenderOp
 		float32(a) / 65535.0,
 	}
 
-	const dpi = 96.0
-	scale := options.Size / 72.0 * dpi / 32.0
-
-	cursorX := options.X
-	cursorY := options.Y
-	ppem := fixed.Int26_6(32 << 6)
+	fontRegion, exists := renderer.TextureManager.region(renderer.FontTextureID)
+	if !exists {
+		diag.Errorf("Font texture not found")
+		return
+	}
+	// glyph coordinates are relative to the font image, which is only part of the atlas
+	fontBounds := fontRegion.bounds
+	toAtlasU := func(u float32) float32 {
+		return (float32(fontBounds.Min.X) + u*float32(fontBounds.Dx())) / float32(fontRegion.width)
+	}
+	toAtlasV := func(v float32) float32 {
+		return (float32(fontBounds.Min.Y) + v*float32(fontBounds.Dy())) / float32(fontRegion.height)
+	}
 
-	var prevRune rune
+	renderer.useTexture(fontRegion.glTextureID)
 
 	width, height := renderer.GetViewportSize()
-	for _, r := range text {
-		if r == '\n' {
-			continue
-		}
-
-		glyph, exists := renderer.Font.Glyphs[r]
-		if !exists {
-			log.Printf("Glyph for rune '%c' not found", r)
-			continue
-		}
-
-		if prevRune != 0 {
-			kern, err := renderer.Font.GetKerning(prevRune, r, ppem)
-			if err == nil {
-				kerning := float32(kern) / 64.0 * scale
-				cursorX += kerning
-			}
-		}
-
-		xpos := cursorX + glyph.BearingX*scale
-		ypos := cursorY - (glyph.SizeHeight-glyph.BearingY)*scale
-		ypos = bruteForceFixFloaters(glyph.Rune, ypos, options.Size)
-
-		w := glyph.SizeWidth * scale
-		h := glyph.SizeHeight * scale
-
-		u0, v0, u1, v1 := glyph.TexCoords[0], glyph.TexCoords[1], glyph.TexCoords[2], glyph.TexCoords[3]
+	renderer.Font.Layout(text, options.X, options.Y, options.Size, func(glyph *font.Glyph, xpos, ypos, w, h float32) {
+		u0, v0 := toAtlasU(glyph.TexCoords[0]), toAtlasV(glyph.TexCoords[1])
+		u1, v1 := toAtlasU(glyph.TexCoords[2]), toAtlasV(glyph.TexCoords[3])
 		v0, v1 = v1, v0
 
 		normX0 := (xpos/float32(width))*2.0 - 1.0
@@ -375,56 +470,43 @@ func (renderer *Renderer) RenderText(text string, options *graphics.TextRenderOp
 		}
 
 		if err := renderer.ensureCapacityForVertices(len(vertices)); err != nil {
-			logrus.Errorf("Failed to ensure capacity: %v", err)
-			break
-		}
-
-		baseIndex := renderer.VertexCount
-		if baseIndex+len(vertices) > len(renderer.Vertices) {
-			log.Println("Not enough space in Vertices slice to add text")
+			diag.Errorf("Failed to ensure capacity: %v", err)
 			return
 		}
 
-		copy(renderer.Vertices[baseIndex:], vertices)
+		copy(renderer.Vertices[renderer.VertexCount:], vertices)
 		renderer.VertexCount += len(vertices)
-
-		cursorX += glyph.AdvanceWidth * scale
-
-		prevRune = r
-	}
+	})
 }
 
 func (renderer *Renderer) RenderFramebuffer(fb graphics.Framebuffer, options *graphics.TextureRenderOptions) {
-	options.TextureIndex = float32(fb.GetTextureID())
+	if source, ok := fb.(*Framebuffer); ok && source != renderer.current.framebuffer {
+		renderer.current.dependencies[source] = true
+	}
+	renderer.useTexture(fb.GetTextureID())
 	renderer.renderTexture(options)
 }
 
 func (renderer *Renderer) RenderTexture(textureID uint32, options *graphics.TextureRenderOptions) {
-	tm := renderer.TextureManager
-	bounds, exists := tm.textureBounds[textureID]
+	region, exists := renderer.TextureManager.region(textureID)
 	if !exists {
-		logrus.Error("Texture handle not found")
+		diag.Errorf("Texture handle not found")
 		return
 	}
-	options.RectX = float32(bounds.Min.X) + options.RectX
-	options.RectY = float32(bounds.Min.Y) + options.RectY
-	options.Width = float32(tm.atlas.Width)
-	options.Height = float32(tm.atlas.Height)
+	options.RectX = float32(region.bounds.Min.X) + options.RectX
+	options.RectY = float32(region.bounds.Min.Y) + options.RectY
+	options.Width = float32(region.width)
+	options.Height = float32(region.height)
+	renderer.useTexture(region.glTextureID)
 	renderer.renderTexture(options)
 }
 
 func (renderer *Renderer) renderTexture(options *graphics.TextureRenderOptions) {
-	const verticesPerTexture = 6
-	if renderer.VertexCount+verticesPerTexture > len(renderer.Vertices) {
-		if err := renderer.ensureCapacityForVertices(verticesPerTexture); err != nil {
-			logrus.Errorf("Failed to ensure capacity: %v", err)
-			return
-		}
-	}
-
-	screenWidth, screenHeight := renderer.GetViewportSize()
-	normX, normY := normalizeCoordinates(options.X, options.Y, screenWidth, screenHeight)
+	renderer.renderTextureQuad(options, graphics.OP_CODE_TEXTURE)
+}
 
+// renderTextureQuad queues the quad of a texture drawn with a texture sampling op code.
+func (renderer *Renderer) renderTextureQuad(options *graphics.TextureRenderOptions, opCode graphics.OpCode) {
 	width := options.DesiredWidth
 	if width == 0 {
 		width = options.RectWidth * options.Scale
@@ -448,79 +530,90 @@ func (renderer *Renderer) renderTexture(options *graphics.TextureRenderOptions)
 		v0, v1 = v1, v0
 	}
 
-	vertices := []graphics.Vertex{
-		{
-			FsQuadPos:  [2]float32{normX, normY},
-			LocalPos:   [2]float32{0, 0},
-			TexCoord:   [2]float32{u0, v1},
-			OpCode:     graphics.OP_CODE_TEXTURE,
-			Color:      [4]float32{1.0, 1.0, 1.0, 1.0},
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
-		},
-		{
-			FsQuadPos:  [2]float32{normX, normY - height},
-			LocalPos:   [2]float32{0, -height},
-			TexCoord:   [2]float32{u0, v0},
-			OpCode:     graphics.OP_CODE_TEXTURE,
-			Color:      [4]float32{1.0, 1.0, 1.0, 1.0},
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
-		},
-		{
-			FsQuadPos:  [2]float32{normX + width, normY - height},
-			LocalPos:   [2]float32{width, -height},
-			TexCoord:   [2]float32{u1, v0},
-			OpCode:     graphics.OP_CODE_TEXTURE,
-			Color:      [4]float32{1.0, 1.0, 1.0, 1.0},
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
-		},
-		{
-			FsQuadPos:  [2]float32{normX, normY},
-			LocalPos:   [2]float32{0, 0},
-			TexCoord:   [2]float32{u0, v1},
-			OpCode:     graphics.OP_CODE_TEXTURE,
-			Color:      [4]float32{1.0, 1.0, 1.0, 1.0},
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
-		},
-		{
-			FsQuadPos:  [2]float32{normX + width, normY - height},
-			LocalPos:   [2]float32{width, -height},
-			TexCoord:   [2]float32{u1, v0},
-			OpCode:     graphics.OP_CODE_TEXTURE,
-			Color:      [4]float32{1.0, 1.0, 1.0, 1.0},
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
-		},
-		{
-			FsQuadPos:  [2]float32{normX + width, normY},
-			LocalPos:   [2]float32{width, 0},
-			TexCoord:   [2]float32{u1, v1},
-			OpCode:     graphics.OP_CODE_TEXTURE,
-			Color:      [4]float32{1.0, 1.0, 1.0, 1.0},
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
-		},
+	renderer.appendQuad(options, width, height, [2]float32{u0, v1}, [2]float32{u1, v0}, graphics.Vertex{
+		OpCode:       opCode,
+		Color:        textureColor(options),
+		TextureIndex: options.TextureIndex,
+	})
+}
+
+// appendQuad queues a quad of the given size positioned, skewed and rotated by the options.
+// texTopLeft and texBottomRight are the texture coordinates of the matching corners
+// and every other vertex attribute is copied from the template.
+func (renderer *Renderer) appendQuad(options *graphics.TextureRenderOptions, width, height float32, texTopLeft, texBottomRight [2]float32, template graphics.Vertex) {
+	const verticesPerQuad = 6
+	if renderer.VertexCount+verticesPerQuad > len(renderer.Vertices) {
+		if err := renderer.ensureCapacityForVertices(verticesPerQuad); err != nil {
+			diag.Errorf("Failed to ensure capacity: %v", err)
+			return
+		}
+	}
+
+	screenWidth, screenHeight := renderer.GetViewportSize()
+	normX, normY := normalizeCoordinates(options.X, options.Y, screenWidth, screenHeight)
+	template.Resolution = [2]float32{float32(screenWidth), float32(screenHeight)}
+
+	// corners are in pixels relative to the pivot with y pointing down the screen
+	originX := options.OriginX * width
+	originY := options.OriginY * height
+	left, top := -originX, -originY
+	right, bottom := width-originX, height-originY
+
+	corners := [verticesPerQuad][2]float32{
+		{left, top},
+		{left, bottom},
+		{right, bottom},
+		{left, top},
+		{right, bottom},
+		{right, top},
+	}
+	u0, u1 := texTopLeft[0], texBottomRight[0]
+	vTop, vBottom := texTopLeft[1], texBottomRight[1]
+	texCoords := [verticesPerQuad][2]float32{
+		{u0, vTop},
+		{u0, vBottom},
+		{u1, vBottom},
+		{u0, vTop},
+		{u1, vBottom},
+		{u1, vTop},
 	}
 
+	skewX := float32(math.Tan(float64(options.SkewX)))
+	skewY := float32(math.Tan(float64(options.SkewY)))
 	cosTheta := float32(math.Cos(float64(options.Rotation)))
 	sinTheta := float32(math.Sin(float64(options.Rotation)))
 
-	for i := 0; i < verticesPerTexture; i++ {
-		v := &vertices[i]
-		v.TextureIndex = options.TextureIndex
-
-		localX := v.LocalPos[0]
-		localY := v.LocalPos[1]
+	vertices := renderer.Vertices[renderer.VertexCount : renderer.VertexCount+verticesPerQuad]
+	for i := 0; i < verticesPerQuad; i++ {
+		localX := corners[i][0] + corners[i][1]*skewX
+		localY := -(corners[i][1] + corners[i][0]*skewY)
 
 		rotatedX := localX*cosTheta - localY*sinTheta
 		rotatedY := localX*sinTheta + localY*cosTheta
 
-		v.FsQuadPos[0] = normX + rotatedX/float32(screenWidth)*2.0
-		v.FsQuadPos[1] = normY + rotatedY/float32(screenHeight)*2.0
-
-		v.LocalPos[0] = rotatedX
-		v.LocalPos[1] = rotatedY
+		v := template
+		v.FsQuadPos = [2]float32{
+			normX + rotatedX/float32(screenWidth)*2.0,
+			normY + rotatedY/float32(screenHeight)*2.0,
+		}
+		v.LocalPos = [2]float32{rotatedX, rotatedY}
+		v.TexCoord = texCoords[i]
+		vertices[i] = v
 	}
 
-	copy(renderer.Vertices[renderer.VertexCount:], vertices)
-	renderer.VertexCount += verticesPerTexture
+	renderer.VertexCount += verticesPerQuad
+}
+
+// textureColor combines the tint and alpha of the options into the color the texels are multiplied by.
+func textureColor(options *graphics.TextureRenderOptions) [4]float32 {
+	color := [4]float32{1.0, 1.0, 1.0, 1.0}
+	if options.Tint != nil {
+		color = toRGBA(options.Tint)
+	}
+	if options.Alpha != nil {
+		color[3] *= *options.Alpha
+	}
+	return color
 }
 
 func normalizeCoordinates(x, y float32, screenWidth, screenHeight int) (float32, float32) {
@@ -538,27 +631,52 @@ func (renderer *Renderer) GetViewportSize() (int, int) {
 func (renderer *Renderer) LoadFont(fontData []byte) (graphics.Font, error) {
 	font, err := font.LoadFont(fontData)
 	if err != nil {
-		logrus.Error(err)
+		diag.Errorf("Failed to load font: %v", err)
+		return nil, err
 	}
 	renderer.Font = font
-	return font, err
+	renderer.uploadFont()
+	return font, nil
 }
 
+// uploadFont places the glyph image of the current font in the atlas, replacing the previous font's.
+// Glyph coordinates are measured from the top of the image, so it is flipped here
+// to cancel the flip done on upload.
+func (renderer *Renderer) uploadFont() {
+	previous := renderer.FontTextureID
+	fontImg := renderer.Font.Image()
+	fontImg = flipImageVertically(fontImg)
+	renderer.FontTextureID = renderer.TextureManager.UploadTexture(fontImg)
+	if previous != 0 {
+		renderer.TextureManager.DeleteTexture(previous)
+	}
+}
+
+// BindFramebuffer sends the geometry queued from now on to a framebuffer until it is unbound.
+// Binding nil returns to the screen.
 func (renderer *Renderer) BindFramebuffer(fb graphics.Framebuffer) {
-	if fb != nil {
-		fb.Bind()
-		gl.Viewport(0, 0, int32(fb.GetWidth()), int32(fb.GetHeight()))
-	} else {
-		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-		width, height := renderer.GetViewportSize()
-		gl.Viewport(0, 0, int32(width), int32(height))
+	if fb == nil {
+		renderer.passStack = renderer.passStack[:0]
+		renderer.switchPass(renderer.screenPass)
+		return
+	}
+	target, ok := fb.(*Framebuffer)
+	if !ok {
+		diag.Errorf("Can not bind framebuffer of type %T", fb)
+		return
 	}
+	renderer.passStack = append(renderer.passStack, renderer.current)
+	renderer.switchPass(renderer.passFor(target))
 }
 
+// UnbindFramebuffer returns to the target that was bound before the current framebuffer.
 func (renderer *Renderer) UnbindFramebuffer() {
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-	width, height := renderer.GetViewportSize()
-	gl.Viewport(0, 0, int32(width), int32(height))
+	previous := renderer.screenPass
+	if n := len(renderer.passStack); n > 0 {
+		previous = renderer.passStack[n-1]
+		renderer.passStack = renderer.passStack[:n-1]
+	}
+	renderer.switchPass(previous)
 }
 
 func toRGBA(c color.Color) [4]float32 {
@@ -2,11 +2,36 @@ package opengl
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
-	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/dfirebaugh/banana/graphics/opengl/shaders"
+	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
+// ShaderCompileError is returned when GLSL fails to compile or link.
+// Log is the driver's info log, which names the offending lines.
+type ShaderCompileError struct {
+	// Stage is "vertex", "fragment" or "link".
+	Stage string
+	Log   string
+}
+
+func (e *ShaderCompileError) Error() string {
+	if e.Stage == "link" {
+		return fmt.Sprintf("failed to link program: %s", e.Log)
+	}
+	return fmt.Sprintf("failed to compile %s shader: %s", e.Stage, e.Log)
+}
+
+func shaderStage(shaderType uint32) string {
+	if shaderType == gl.VERTEX_SHADER {
+		return "vertex"
+	}
+	return "fragment"
+}
+
 func compileShader(source string, shaderType uint32) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
 
@@ -24,7 +49,8 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
 
-		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
+		gl.DeleteShader(shader)
+		return 0, &ShaderCompileError{Stage: shaderStage(shaderType), Log: strings.TrimRight(log, "\x00\n")}
 	}
 
 	return shader, nil
@@ -38,6 +64,7 @@ func newShaderProgram(vertexShaderSource, fragmentShaderSource string) (uint32,
 
 	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
 	if err != nil {
+		gl.DeleteShader(vertexShader)
 		return 0, err
 	}
 
@@ -55,7 +82,10 @@ func newShaderProgram(vertexShaderSource, fragmentShaderSource string) (uint32,
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
 
-		return 0, fmt.Errorf("failed to link program: %v", log)
+		gl.DeleteProgram(program)
+		gl.DeleteShader(vertexShader)
+		gl.DeleteShader(fragmentShader)
+		return 0, &ShaderCompileError{Stage: "link", Log: strings.TrimRight(log, "\x00\n")}
 	}
 
 	gl.DeleteShader(vertexShader)
@@ -63,3 +93,151 @@ func newShaderProgram(vertexShaderSource, fragmentShaderSource string) (uint32,
 
 	return program, nil
 }
+
+// Shader is a program compiled from user supplied GLSL.
+// Uniform values are stored when they are set and sent to the GPU whenever the shader is used,
+// so they can be set at any time, including outside of rendering.
+type Shader struct {
+	Program   uint32
+	locations map[string]int32
+	values    map[string]func(location int32)
+	// vertexPath and fragmentPath are set for shaders loaded from files.
+	vertexPath, fragmentPath string
+	target                   shaders.Target
+	// unused holds the uniforms that were set but are not in the program, so each is only reported once.
+	unused map[string]bool
+}
+
+// NewShader compiles a shader for a GLSL target. An empty vertex source uses a vertex shader that covers
+// the viewport and passes the texture coordinate to the fragment shader as `in vec2 v_uv`.
+func NewShader(vertexSource, fragmentSource string, target shaders.Target) (*Shader, error) {
+	program, err := compileUserShader(vertexSource, fragmentSource, target)
+	if err != nil {
+		return nil, err
+	}
+	return &Shader{
+		Program:   program,
+		locations: make(map[string]int32),
+		values:    make(map[string]func(location int32)),
+		target:    target,
+		unused:    make(map[string]bool),
+	}, nil
+}
+
+// compileUserShader compiles GLSL written by users. Desktop contexts compile it with the version it declares,
+// while OpenGL ES needs it translated.
+func compileUserShader(vertexSource, fragmentSource string, target shaders.Target) (uint32, error) {
+	if vertexSource == "" {
+		vertexSource = shaders.Translate(shaders.FullscreenVertexShaderSource, target)
+	} else if target == shaders.GLSLES300 {
+		vertexSource = shaders.Translate(vertexSource, target)
+	}
+	if target == shaders.GLSLES300 {
+		fragmentSource = shaders.Translate(fragmentSource, target)
+	}
+	return newShaderProgram(vertexSource, fragmentSource)
+}
+
+func (s *Shader) location(name string) int32 {
+	location, ok := s.locations[name]
+	if !ok {
+		location = gl.GetUniformLocation(s.Program, gl.Str(name+"\x00"))
+		s.locations[name] = location
+	}
+	return location
+}
+
+func (s *Shader) SetFloat(name string, v float32) {
+	s.values[name] = func(location int32) { gl.Uniform1f(location, v) }
+}
+
+func (s *Shader) SetInt(name string, v int32) {
+	s.values[name] = func(location int32) { gl.Uniform1i(location, v) }
+}
+
+func (s *Shader) SetVec2(name string, x, y float32) {
+	s.values[name] = func(location int32) { gl.Uniform2f(location, x, y) }
+}
+
+func (s *Shader) SetVec3(name string, x, y, z float32) {
+	s.values[name] = func(location int32) { gl.Uniform3f(location, x, y, z) }
+}
+
+func (s *Shader) SetVec4(name string, x, y, z, w float32) {
+	s.values[name] = func(location int32) { gl.Uniform4f(location, x, y, z, w) }
+}
+
+// SetMat4 sets a matrix given in column-major order.
+func (s *Shader) SetMat4(name string, m [16]float32) {
+	s.values[name] = func(location int32) { gl.UniformMatrix4fv(location, 1, false, &m[0]) }
+}
+
+// use makes the shader current and uploads its uniforms.
+func (s *Shader) use() {
+	gl.UseProgram(s.Program)
+	for name, apply := range s.values {
+		location := s.location(name)
+		if location != -1 {
+			apply(location)
+			continue
+		}
+		if !s.unused[name] {
+			s.unused[name] = true
+			diag.Warnf("Uniform %q is set but the shader does not use it. Check the spelling; "+
+				"uniforms that do not affect the output are removed by the GLSL compiler", name)
+		}
+	}
+}
+
+func (s *Shader) Destroy() {
+	gl.DeleteProgram(s.Program)
+}
+
+// NewShaderFromFiles compiles a shader from GLSL files. An empty vertexPath uses the full-screen vertex shader.
+// Shaders loaded from files are recompiled when the files change while hot reloading is enabled.
+func NewShaderFromFiles(vertexPath, fragmentPath string, target shaders.Target) (*Shader, error) {
+	vertexSource, fragmentSource, err := readShaderFiles(vertexPath, fragmentPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewShader(vertexSource, fragmentSource, target)
+	if err != nil {
+		return nil, err
+	}
+	s.vertexPath = vertexPath
+	s.fragmentPath = fragmentPath
+	return s, nil
+}
+
+func readShaderFiles(vertexPath, fragmentPath string) (string, string, error) {
+	var vertexSource string
+	if vertexPath != "" {
+		source, err := os.ReadFile(vertexPath)
+		if err != nil {
+			return "", "", err
+		}
+		vertexSource = string(source)
+	}
+	fragmentSource, err := os.ReadFile(fragmentPath)
+	if err != nil {
+		return "", "", err
+	}
+	return vertexSource, string(fragmentSource), nil
+}
+
+// reload recompiles a shader from its files. The current program is kept when compiling fails.
+func (s *Shader) reload() error {
+	vertexSource, fragmentSource, err := readShaderFiles(s.vertexPath, s.fragmentPath)
+	if err != nil {
+		return err
+	}
+	program, err := compileUserShader(vertexSource, fragmentSource, s.target)
+	if err != nil {
+		return err
+	}
+	gl.DeleteProgram(s.Program)
+	s.Program = program
+	clear(s.locations)
+	clear(s.unused)
+	return nil
+}
@@ -0,0 +1,10 @@
+#version 330 core
+
+// Covers the viewport with a single triangle generated from the vertex index.
+out vec2 v_uv;
+
+void main() {
+    vec2 pos = vec2(float((gl_VertexID << 1) & 2), float(gl_VertexID & 2));
+    v_uv = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
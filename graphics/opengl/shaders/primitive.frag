@@ -1,4 +1,4 @@
-#version 460 core
+#version 330 core
 in vec2 local_pos;
 in float op_code;
 in float radius;
@@ -8,16 +8,27 @@ in float height;
 in vec2 tex_coord;
 in float texture_index;
 in float font_index;
+in vec4 tex_rect;
+in float wrap_mode;
+in float stroke_width;
+in vec4 stroke_color;
 
 out vec4 fragColor;
 
-uniform sampler2D samplers[24];
+// the sampler array and sampleSlot are inserted here, sized to the texture units of the GPU
+// @samplers
 
 const float OP_CODE_VERTEX = 1.0;
 const float OP_CODE_CIRCLE = 2.0;
 const float OP_CODE_RECT = 3.0;
 const float OP_CODE_TEXT = 4.0;
 const float OP_CODE_TEXTURE = 5.0;
+const float OP_CODE_TEXTURE_TILED = 6.0;
+const float OP_CODE_TEXTURE_INDEXED = 7.0;
+
+const float WRAP_REPEAT = 0.0;
+const float WRAP_MIRROR = 1.0;
+const float WRAP_CLAMP = 2.0;
 
 float sdCircle(vec2 p, float r) {
     return length(p) - r;
@@ -26,7 +37,7 @@ float sdCircle(vec2 p, float r) {
 float sdRoundedRect(vec2 p, vec2 bounds, float r) {
     vec2 b = bounds - vec2(r);
     vec2 q = abs(p) - b;
-    return length(max(q, 0.0)) - r;
+    return length(max(q, 0.0)) + min(max(q.x, q.y), 0.0) - r;
 }
 
 float sdEquilateralTriangle(vec2 p) {
@@ -38,6 +49,33 @@ float sdEquilateralTriangle(vec2 p) {
     return -length(p) * sign(p.y);
 }
 
+// shadeSDF colors a fragment from its signed distance to a shape's edge in pixels.
+// The edge is anti-aliased and, when stroke_width is set, a stroke is drawn along the inside of the edge.
+vec4 shadeSDF(float d) {
+    float aa = max(fwidth(d), 0.0001);
+    float coverage = clamp(0.5 - d / aa, 0.0, 1.0);
+    vec4 c = color;
+    if (stroke_width > 0.0) {
+        float fill = clamp(0.5 - (d + stroke_width) / aa, 0.0, 1.0);
+        c = mix(stroke_color, color, fill);
+    }
+    return vec4(c.rgb, c.a * coverage);
+}
+
+// custom shape distance functions are inserted here
+// @custom-sdf-functions
+
+float wrapCoord(float t, float mode) {
+    if (mode == WRAP_MIRROR) {
+        float m = mod(t, 2.0);
+        return m > 1.0 ? 2.0 - m : m;
+    }
+    if (mode == WRAP_CLAMP) {
+        return clamp(t, 0.0, 1.0);
+    }
+    return fract(t);
+}
+
 void main() {
     vec2 p = local_pos;
     fragColor = vec4(color.rgb, 0.0);
@@ -45,24 +83,33 @@ void main() {
     if (op_code == OP_CODE_VERTEX) {
         fragColor = color;
     } else if (op_code == OP_CODE_CIRCLE) {
-        float sdf = sdCircle(p, radius);
-        if (sdf < 0.0) {
-            fragColor = color;
-        }
+        fragColor = shadeSDF(sdCircle(p, radius));
     } else if (op_code == OP_CODE_RECT) {
-        float sdf = sdRoundedRect(p, vec2(width, height) * 0.5, radius);
-        if (sdf < 0.0) {
-            fragColor = color;
-        }
+        fragColor = shadeSDF(sdRoundedRect(p, vec2(width, height) * 0.5, radius));
     }
+    // custom shapes are dispatched here
+    // @custom-sdf-dispatch
     if (op_code == OP_CODE_TEXT) {
         int idx = int(font_index);
-        float alpha = texture(samplers[idx], tex_coord).a;
+        float alpha = sampleSlot(idx, tex_coord).a;
         fragColor = vec4(color.rgb, alpha * color.a);
     }
 
     if (op_code == OP_CODE_TEXTURE) {
         int idx = int(texture_index);
-        fragColor = texture(samplers[idx], tex_coord);
+        fragColor = sampleSlot(idx, tex_coord) * color;
+    }
+
+    if (op_code == OP_CODE_TEXTURE_TILED) {
+        int idx = int(texture_index);
+        vec2 t = vec2(wrapCoord(tex_coord.x, wrap_mode), wrapCoord(tex_coord.y, wrap_mode));
+        vec2 uv = mix(tex_rect.xy, tex_rect.zw, t);
+        fragColor = sampleSlot(idx, uv) * color;
+    }
+
+    if (op_code == OP_CODE_TEXTURE_INDEXED) {
+        float index = sampleSlot(int(texture_index), tex_coord).r * 255.0;
+        vec2 paletteUV = vec2((index + 0.5) / 256.0, 0.5);
+        fragColor = sampleSlot(int(font_index), paletteUV) * color;
     }
 }
@@ -1,4 +1,4 @@
-#version 460 core
+#version 330 core
 
 layout(location = 0) in vec2 in_pos;
 layout(location = 1) in vec2 in_shape_pos;
@@ -12,6 +12,10 @@ layout(location = 8) in vec2 in_tex_coord;
 layout(location = 9) in vec2 in_resolution;
 layout(location = 10) in float in_texture_index;
 layout(location = 11) in float in_font_index;
+layout(location = 12) in vec4 in_tex_rect;
+layout(location = 13) in float in_wrap_mode;
+layout(location = 14) in float in_stroke_width;
+layout(location = 15) in vec4 in_stroke_color;
 
 out vec2 local_pos;
 out float op_code;
@@ -20,14 +24,16 @@ out vec4 color;
 out float width;
 out float height;
 out vec2 tex_coord;
-out uint stencil_write_value;
-out uint stencil_test_value;
 out float texture_index;
 out float font_index;
+out vec4 tex_rect;
+out float wrap_mode;
+out float stroke_width;
+out vec4 stroke_color;
 
 void main() {
     vec2 scaledShapePos = in_shape_pos;
-    if (in_op_code == 4.0 || in_op_code == 5.0) {
+    if (in_op_code == 4.0 || in_op_code == 5.0 || in_op_code == 6.0 || in_op_code == 7.0) {
         gl_Position = vec4(in_pos, 0.0, 1.0);
     } else {
         gl_Position = vec4(scaledShapePos + in_local_pos / in_resolution * 2.0, 0.0, 1.0);
@@ -41,4 +47,8 @@ void main() {
     tex_coord = in_tex_coord;
     texture_index = in_texture_index;
     font_index = in_font_index;
+    tex_rect = in_tex_rect;
+    wrap_mode = in_wrap_mode;
+    stroke_width = in_stroke_width;
+    stroke_color = in_stroke_color;
 }
@@ -2,10 +2,51 @@ package shaders
 
 import (
 	_ "embed"
+	"fmt"
+	"strings"
 )
 
+// The embedded shaders are written in GLSL 3.30 core. Translate produces the variant a context needs.
+
 //go:embed primitive.vert
 var VertexShaderSource string
 
 //go:embed primitive.frag
 var FragmentShaderSource string
+
+// FullscreenVertexShaderSource is the vertex shader used by post processing shaders that only supply a fragment shader.
+//
+//go:embed fullscreen.vert
+var FullscreenVertexShaderSource string
+
+// Target is the GLSL dialect a shader is compiled as.
+type Target int
+
+const (
+	// GLSL330 runs on every desktop context from OpenGL 3.3 core up.
+	GLSL330 Target = iota
+	// GLSLES300 runs on OpenGL ES 3.0 contexts.
+	GLSLES300
+)
+
+func (t Target) header() string {
+	if t == GLSLES300 {
+		return "#version 300 es\nprecision highp float;\nprecision highp int;\n"
+	}
+	return "#version 330 core\n"
+}
+
+// Translate replaces the #version directive of a shader with the one of a target,
+// adding the default precision statements GLSL ES requires.
+// Line numbers in compile errors still refer to the original source.
+func Translate(source string, target Target) string {
+	lines := strings.SplitAfter(source, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#version") {
+			before := strings.Join(lines[:i], "")
+			after := strings.Join(lines[i+1:], "")
+			return fmt.Sprintf("%s%s#line %d\n%s", before, target.header(), i+2, after)
+		}
+	}
+	return target.header() + "#line 1\n" + source
+}
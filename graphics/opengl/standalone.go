@@ -0,0 +1,84 @@
+package opengl
+
+import (
+	"image"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// maxAtlasEntrySize is the largest width or height a texture can have and still be packed into the atlas.
+const maxAtlasEntrySize = 1024
+
+// StandaloneTexture is a texture that lives in its own GL texture instead of the shared atlas.
+// It can be filtered and mipmapped independently of every other texture.
+type StandaloneTexture struct {
+	ID      uint32
+	Width   int
+	Height  int
+	Options graphics.TextureOptions
+}
+
+func newStandaloneTexture(img *image.RGBA, options graphics.TextureOptions) *StandaloneTexture {
+	bounds := img.Bounds()
+	t := &StandaloneTexture{
+		Width:   bounds.Dx(),
+		Height:  bounds.Dy(),
+		Options: options,
+	}
+
+	gl.GenTextures(1, &t.ID)
+	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(t.Width), int32(t.Height), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	t.applyParameters()
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return t
+}
+
+// applyParameters sets the filtering of the texture and rebuilds its mipmaps. The texture must be bound.
+func (t *StandaloneTexture) applyParameters() {
+	minFilter, magFilter := int32(gl.NEAREST), int32(gl.NEAREST)
+	if t.Options.Filter == graphics.FilterLinear {
+		minFilter, magFilter = gl.LINEAR, gl.LINEAR
+	}
+	if t.Options.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+		minFilter = gl.NEAREST_MIPMAP_NEAREST
+		if t.Options.Filter == graphics.FilterLinear {
+			minFilter = gl.LINEAR_MIPMAP_LINEAR
+		}
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, magFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+}
+
+// update replaces part of the texture with an image that has already been flipped vertically.
+// xOffset and yOffset are measured from the top-left of the texture.
+func (t *StandaloneTexture) update(img *image.RGBA, xOffset, yOffset int) {
+	bounds := img.Bounds()
+	glY := t.Height - yOffset - bounds.Dy()
+
+	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(img.Stride/4))
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(xOffset), int32(glY), int32(bounds.Dx()), int32(bounds.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	if t.Options.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+func (t *StandaloneTexture) Destroy() {
+	gl.DeleteTextures(1, &t.ID)
+}
+
+// needsStandalone reports whether a texture cannot share the nearest filtered atlas.
+func needsStandalone(bounds image.Rectangle, options graphics.TextureOptions) bool {
+	return options.Standalone ||
+		options.Mipmaps ||
+		options.Filter != graphics.FilterNearest ||
+		bounds.Dx() > maxAtlasEntrySize ||
+		bounds.Dy() > maxAtlasEntrySize
+}
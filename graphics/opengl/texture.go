@@ -2,36 +2,47 @@ package opengl
 
 import (
 	"image"
-	"image/color"
 	"image/draw"
+	"sort"
 
-	"github.com/go-gl/gl/v4.6-core/gl"
-	"github.com/sirupsen/logrus"
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
+const (
+	// initialAtlasSize is the width and height of a new atlas page.
+	initialAtlasSize = 512
+	// maxAtlasSize caps how far a page grows, even when the driver allows larger textures,
+	// since every page is mirrored in memory.
+	maxAtlasSize = 8192
+)
+
+// TextureAtlas is one page of the atlas. Textures are packed into the first page with room for them
+// and a new page is started once every page has reached the size limit.
 type TextureAtlas struct {
 	ID     uint32
 	Width  int
 	Height int
 	Image  *image.RGBA
-}
 
-func (atlas *TextureAtlas) canFit(width, height int) bool {
-	return width <= atlas.Width && height <= atlas.Height
+	packer *maxRectsPacker
+	// resized is set when the GL texture no longer matches the size of Image.
+	resized bool
 }
 
-func (atlas *TextureAtlas) grow(newImgWidth, newImgHeight int) {
-	requiredWidth := atlas.Width + newImgWidth
-	requiredHeight := atlas.Height + newImgHeight
-
-	newWidth := nextPowerOfTwo(requiredWidth)
-	newHeight := nextPowerOfTwo(requiredHeight)
-
-	if newWidth <= atlas.Width {
-		newWidth = atlas.Width * 2
+// grow doubles the atlas until it is at least width by height, keeping the existing contents in place.
+func (atlas *TextureAtlas) grow(width, height int) {
+	newWidth, newHeight := atlas.Width, atlas.Height
+	for newWidth < width {
+		newWidth *= 2
+	}
+	for newHeight < height {
+		newHeight *= 2
 	}
-	if newHeight <= atlas.Height {
-		newHeight = atlas.Height * 2
+	if newWidth == atlas.Width && newHeight == atlas.Height {
+		newWidth *= 2
+		newHeight *= 2
 	}
 
 	newImage := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
@@ -40,6 +51,7 @@ func (atlas *TextureAtlas) grow(newImgWidth, newImgHeight int) {
 	atlas.Width = newWidth
 	atlas.Height = newHeight
 	atlas.Image = newImage
+	atlas.resized = true
 }
 
 func nextPowerOfTwo(n int) int {
@@ -56,32 +68,56 @@ func nextPowerOfTwo(n int) int {
 	return n
 }
 
-func (atlas *TextureAtlas) findPlace(width, height int) (int, int) {
-	for y := 0; y <= atlas.Height-height; y++ {
-		for x := 0; x <= atlas.Width-width; x++ {
-			if atlas.isAreaFree(x, y, width, height) {
-				return x, y
-			}
-		}
+func newAtlasPage(size int) *TextureAtlas {
+	return &TextureAtlas{
+		Width:  size,
+		Height: size,
+		Image:  image.NewRGBA(image.Rect(0, 0, size, size)),
+		packer: newMaxRectsPacker(size, size),
 	}
-	return -1, -1
 }
 
-func (atlas *TextureAtlas) isAreaFree(x, y, width, height int) bool {
-	for i := 0; i < height; i++ {
-		for j := 0; j < width; j++ {
-			if atlas.Image.At(x+j, y+i) != (color.RGBA{0, 0, 0, 0}) {
-				return false
-			}
+// allocate reserves space for a width by height image plus its padding,
+// growing the page up to maxSize when it is full. The returned rectangle excludes the padding.
+func (atlas *TextureAtlas) allocate(width, height, maxSize int) (image.Rectangle, bool) {
+	paddedWidth, paddedHeight := width+2*atlasPadding, height+2*atlasPadding
+	for {
+		rect, ok := atlas.packer.Insert(paddedWidth, paddedHeight)
+		if ok {
+			return rect.Inset(atlasPadding), true
+		}
+		if (atlas.Width >= maxSize && atlas.Height >= maxSize) || paddedWidth > maxSize || paddedHeight > maxSize {
+			return image.Rectangle{}, false
 		}
+		atlas.grow(min(max(atlas.Width, paddedWidth), maxSize), min(max(atlas.Height, paddedHeight), maxSize))
+		atlas.packer.Grow(atlas.Width, atlas.Height)
 	}
-	return true
+}
+
+// uploadRect sends the given area of the atlas image to the GPU.
+// The whole texture is reallocated instead when the atlas has grown since the last upload.
+func (atlas *TextureAtlas) uploadRect(rect image.Rectangle) {
+	if atlas.ID == 0 || atlas.resized {
+		atlas.updateTexture()
+		return
+	}
+	rect = rect.Intersect(atlas.Image.Bounds())
+	if rect.Empty() {
+		return
+	}
+
+	gl.BindTexture(gl.TEXTURE_2D, atlas.ID)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, int32(atlas.Width))
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(rect.Min.X), int32(rect.Min.Y), int32(rect.Dx()), int32(rect.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(atlas.Image.Pix[atlas.Image.PixOffset(rect.Min.X, rect.Min.Y):]))
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
 }
 
 func (atlas *TextureAtlas) updateTexture() {
 	if atlas.ID == 0 {
 		gl.GenTextures(1, &atlas.ID)
 	}
+	atlas.resized = false
 	gl.BindTexture(gl.TEXTURE_2D, atlas.ID)
 	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(atlas.Width), int32(atlas.Height), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(atlas.Image.Pix))
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
@@ -91,32 +127,109 @@ func (atlas *TextureAtlas) updateTexture() {
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 }
 
+func (atlas *TextureAtlas) Destroy() {
+	gl.DeleteTextures(1, &atlas.ID)
+}
+
 type TextureManager struct {
-	atlas         *TextureAtlas
+	// pages always holds at least one page.
+	pages []*TextureAtlas
+	// texturePages maps the handles of atlas textures to the page holding them.
+	texturePages map[uint32]*TextureAtlas
+	// maxPageSize is the size limit of a page, queried from the driver on first use.
+	maxPageSize   int
 	renderer      *Renderer
 	textureBounds map[uint32]image.Rectangle
 	textureIDs    map[uint32]uint32
-	glTextureIDs  map[uint32]image.Rectangle
+	// framebufferBounds is keyed by GL texture name and kept apart from texture handles.
+	framebufferBounds map[uint32]image.Rectangle
+	standalone        map[uint32]*StandaloneTexture
+	indexed           map[uint32]*IndexedTexture
+	// nextTextureID is the next handle to give out. Handles are never reused.
+	nextTextureID uint32
+}
+
+// textureRegion describes where a texture handle lives on the GPU.
+type textureRegion struct {
+	// bounds is the area of the GL texture holding the image, which is stored flipped vertically.
+	bounds        image.Rectangle
+	glTextureID   uint32
+	width, height int
 }
 
 func NewTextureManager(renderer *Renderer) *TextureManager {
-	initialSize := 512
-	atlas := &TextureAtlas{
-		Width:  initialSize,
-		Height: initialSize,
-		Image:  image.NewRGBA(image.Rect(0, 0, initialSize, initialSize)),
-	}
 	return &TextureManager{
-		atlas:         atlas,
-		renderer:      renderer,
-		textureBounds: make(map[uint32]image.Rectangle),
-		textureIDs:    make(map[uint32]uint32),
-		glTextureIDs:  make(map[uint32]image.Rectangle),
+		pages:             []*TextureAtlas{newAtlasPage(initialAtlasSize)},
+		texturePages:      make(map[uint32]*TextureAtlas),
+		renderer:          renderer,
+		textureBounds:     make(map[uint32]image.Rectangle),
+		textureIDs:        make(map[uint32]uint32),
+		framebufferBounds: make(map[uint32]image.Rectangle),
+		standalone:        make(map[uint32]*StandaloneTexture),
+		indexed:           make(map[uint32]*IndexedTexture),
+		nextTextureID:     1,
 	}
 }
 
+// region returns the GL texture and the area within it that belong to a texture handle.
+func (tm *TextureManager) region(textureID uint32) (textureRegion, bool) {
+	bounds, exists := tm.textureBounds[textureID]
+	if !exists {
+		return textureRegion{}, false
+	}
+	if t, ok := tm.standalone[textureID]; ok {
+		return textureRegion{bounds: bounds, glTextureID: t.ID, width: t.Width, height: t.Height}, true
+	}
+	if _, ok := tm.indexed[textureID]; ok {
+		diag.Errorf("Texture ID %d is indexed, draw it with RenderIndexedTexture", textureID)
+		return textureRegion{}, false
+	}
+	page := tm.texturePages[textureID]
+	return textureRegion{bounds: bounds, glTextureID: page.ID, width: page.Width, height: page.Height}, true
+}
+
+// pageSizeLimit returns how large a page may grow on this driver.
+func (tm *TextureManager) pageSizeLimit() int {
+	if tm.maxPageSize == 0 {
+		var maxTextureSize int32
+		gl.GetIntegerv(gl.MAX_TEXTURE_SIZE, &maxTextureSize)
+		tm.maxPageSize = maxAtlasSize
+		if maxTextureSize > 0 {
+			tm.maxPageSize = min(int(maxTextureSize), maxAtlasSize)
+		}
+	}
+	return tm.maxPageSize
+}
+
+// allocate finds room for a width by height image on an existing page,
+// starting a new page when none of them can fit it.
+func (tm *TextureManager) allocate(width, height int) (*TextureAtlas, image.Rectangle, bool) {
+	limit := tm.pageSizeLimit()
+	for _, page := range tm.pages {
+		if rect, ok := page.allocate(width, height, limit); ok {
+			return page, rect, true
+		}
+	}
+
+	page := newAtlasPage(min(initialAtlasSize, limit))
+	rect, ok := page.allocate(width, height, limit)
+	if !ok {
+		return nil, image.Rectangle{}, false
+	}
+	tm.pages = append(tm.pages, page)
+	diag.Infof("Started atlas page %d", len(tm.pages))
+	return page, rect, true
+}
+
+// newTextureID returns a handle that has never been given out before.
+func (tm *TextureManager) newTextureID() uint32 {
+	textureID := tm.nextTextureID
+	tm.nextTextureID++
+	return textureID
+}
+
 func (tm *TextureManager) RegisterTexture(textureID uint32) uint32 {
-	managerID := uint32(len(tm.textureBounds) + 1)
+	managerID := tm.newTextureID()
 	tm.textureIDs[textureID] = managerID
 	return managerID
 }
@@ -127,39 +240,50 @@ func (tm *TextureManager) GetManagerID(textureID uint32) (uint32, bool) {
 }
 
 func (tm *TextureManager) RegisterFramebufferTexture(textureID uint32, width, height int) {
-	textureID += 2
-	tm.textureBounds[textureID] = image.Rect(0, 0, width, height)
-	tm.textureIDs[textureID] = textureID                         // Direct mapping for framebuffer textures
-	tm.glTextureIDs[textureID] = image.Rect(0, 0, width, height) // Store the location in the atlas
-	logrus.Infof("Registered framebuffer texture with ID %d, width %d, height %d", textureID, width, height)
+	tm.framebufferBounds[textureID] = image.Rect(0, 0, width, height)
+	diag.Debugf("Registered framebuffer texture with ID %d, width %d, height %d", textureID, width, height)
 }
 
 func (tm *TextureManager) UploadTexture(img image.Image) uint32 {
+	return tm.UploadTextureWithOptions(img, graphics.TextureOptions{})
+}
+
+func (tm *TextureManager) UploadTextureWithOptions(img image.Image, options graphics.TextureOptions) uint32 {
 	bounds := img.Bounds()
+	if needsStandalone(bounds, options) {
+		return tm.uploadStandaloneTexture(img, options)
+	}
+
 	width, height := bounds.Dx(), bounds.Dy()
 
-	img = flipImageVertically(img)
+	page, rect, ok := tm.allocate(width, height)
+	if !ok {
+		diag.Errorf("Failed to find place for new texture in the atlas")
+		return 0
+	}
+
+	drawExtruded(page.Image, rect, flipImageVertically(img), atlasPadding)
+	page.uploadRect(rect.Inset(-atlasPadding))
 
-	x, y := tm.atlas.findPlace(width, height)
+	textureID := tm.newTextureID()
+	tm.textureBounds[textureID] = rect
+	tm.texturePages[textureID] = page
 
-	if x == -1 || y == -1 {
-		tm.atlas.grow(width, height)
-		x, y = tm.atlas.findPlace(width, height)
-		if x == -1 || y == -1 {
-			logrus.Error("Failed to find place for new texture after growing the atlas")
-			return 0
-		}
-	}
+	x, y := rect.Min.X, rect.Min.Y
+	diag.Debugf("Uploaded texture with ID %d at position (%d, %d)", textureID, x, y)
 
-	draw.Draw(tm.atlas.Image, image.Rect(x, y, x+width, y+height), img, bounds.Min, draw.Src)
+	return textureID
+}
 
-	tm.atlas.updateTexture()
+func (tm *TextureManager) uploadStandaloneTexture(img image.Image, options graphics.TextureOptions) uint32 {
+	bounds := img.Bounds()
+	t := newStandaloneTexture(flipImageVertically(img), options)
 
-	textureID := uint32(len(tm.textureBounds) + 1)
-	tm.textureBounds[textureID] = image.Rect(x, y, x+width, y+height)
-	tm.glTextureIDs[textureID] = image.Rect(x, y, x+width, y+height)
+	textureID := tm.newTextureID()
+	tm.textureBounds[textureID] = image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+	tm.standalone[textureID] = t
 
-	logrus.Infof("Uploaded texture with ID %d at position (%d, %d)", textureID, x, y)
+	diag.Debugf("Uploaded standalone texture with ID %d (%dx%d)", textureID, bounds.Dx(), bounds.Dy())
 
 	return textureID
 }
@@ -168,34 +292,149 @@ func (tm *TextureManager) UpdateTexture(textureID uint32, img image.Image, xOffs
 	bounds := img.Bounds()
 	existingBounds, exists := tm.textureBounds[textureID]
 	if !exists {
-		logrus.Error("Texture ID not found")
+		diag.Errorf("Texture ID not found")
 		return
 	}
 
-	draw.Draw(tm.atlas.Image, existingBounds.Add(image.Pt(xOffset, yOffset)), img, bounds.Min, draw.Src)
+	if t, ok := tm.standalone[textureID]; ok {
+		t.update(flipImageVertically(img), xOffset, yOffset)
+		return
+	}
+	if _, ok := tm.indexed[textureID]; ok {
+		diag.Errorf("Texture ID %d is indexed, update it with UpdateIndexedTexture", textureID)
+		return
+	}
 
-	tm.atlas.updateTexture()
+	// the atlas holds images flipped vertically, so the offset is measured from the bottom of the entry
+	target := image.Rect(
+		existingBounds.Min.X+xOffset,
+		existingBounds.Max.Y-yOffset-bounds.Dy(),
+		existingBounds.Min.X+xOffset+bounds.Dx(),
+		existingBounds.Max.Y-yOffset,
+	)
+	page := tm.texturePages[textureID]
+	entry := page.Image.SubImage(existingBounds).(*image.RGBA)
+	draw.Draw(entry, target, flipImageVertically(img), bounds.Min, draw.Src)
+	extrudeEdges(page.Image, existingBounds, atlasPadding)
+
+	page.uploadRect(existingBounds.Inset(-atlasPadding))
 }
 
+// DeleteTexture releases a texture. Its space in the atlas is reused by later uploads
+// and its handle becomes invalid.
+func (tm *TextureManager) DeleteTexture(textureID uint32) {
+	bounds, exists := tm.textureBounds[textureID]
+	if !exists {
+		diag.Errorf("Texture ID not found")
+		return
+	}
+	if textureID == tm.renderer.FontTextureID {
+		diag.Warnf("The font texture can not be deleted")
+		return
+	}
+
+	delete(tm.textureBounds, textureID)
+
+	if t, ok := tm.standalone[textureID]; ok {
+		t.Destroy()
+		delete(tm.standalone, textureID)
+		return
+	}
+	if t, ok := tm.indexed[textureID]; ok {
+		t.Destroy()
+		delete(tm.indexed, textureID)
+		return
+	}
+
+	page := tm.texturePages[textureID]
+	delete(tm.texturePages, textureID)
+
+	padded := bounds.Inset(-atlasPadding)
+	draw.Draw(page.Image, padded, image.Transparent, image.Point{}, draw.Src)
+	page.packer.Free(padded)
+	page.uploadRect(padded)
+}
+
+// CompactAtlas repacks every texture in the atlas to remove the gaps left by deleted textures,
+// dropping pages that end up empty. Handles stay valid.
+// Call it between frames, since geometry that is already queued keeps the old placement.
+func (tm *TextureManager) CompactAtlas() {
+	type entry struct {
+		id    uint32
+		image *image.RGBA
+	}
+
+	var entries []entry
+	for id, page := range tm.texturePages {
+		bounds := tm.textureBounds[id]
+		img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(img, img.Bounds(), page.Image, bounds.Min, draw.Src)
+		entries = append(entries, entry{id: id, image: img})
+	}
+
+	// placing the tallest textures first packs tighter
+	sort.Slice(entries, func(i, j int) bool {
+		hi, hj := entries[i].image.Bounds().Dy(), entries[j].image.Bounds().Dy()
+		if hi != hj {
+			return hi > hj
+		}
+		return entries[i].id < entries[j].id
+	})
+
+	// the new layout is staged and only replaces the old one once every texture has been placed,
+	// so a failure leaves every handle pointing at its old page
+	oldPages := tm.pages
+	tm.pages = []*TextureAtlas{newAtlasPage(min(initialAtlasSize, tm.pageSizeLimit()))}
+	bounds := make(map[uint32]image.Rectangle, len(entries))
+	pages := make(map[uint32]*TextureAtlas, len(entries))
+	for _, e := range entries {
+		size := e.image.Bounds().Size()
+		page, rect, ok := tm.allocate(size.X, size.Y)
+		if !ok {
+			diag.Errorf("Failed to compact the atlas")
+			tm.pages = oldPages
+			return
+		}
+		drawExtruded(page.Image, rect, e.image, atlasPadding)
+		bounds[e.id] = rect
+		pages[e.id] = page
+	}
+	for id, rect := range bounds {
+		tm.textureBounds[id] = rect
+		tm.texturePages[id] = pages[id]
+	}
+
+	// reuse the GL textures of the old pages rather than allocating new ones
+	for i, page := range oldPages {
+		if i < len(tm.pages) {
+			tm.pages[i].ID = page.ID
+			tm.pages[i].updateTexture()
+			continue
+		}
+		page.Destroy()
+	}
+	for _, page := range tm.pages[min(len(oldPages), len(tm.pages)):] {
+		page.updateTexture()
+	}
+
+	diag.Infof("Compacted the atlas into %d pages with %d textures", len(tm.pages), len(entries))
+}
+
+// flipImageVertically returns a copy of img upside down, with the same bounds.
 func flipImageVertically(img image.Image) *image.RGBA {
 	bounds := img.Bounds()
-	rgbaImg, ok := img.(*image.RGBA)
+	src, ok := img.(*image.RGBA)
 	if !ok {
-		rgbaImg = image.NewRGBA(bounds)
-		draw.Draw(rgbaImg, bounds, img, image.Point{}, draw.Src)
+		src = image.NewRGBA(bounds)
+		draw.Draw(src, bounds, img, bounds.Min, draw.Src)
 	}
 
-	height := bounds.Dy()
-	stride := rgbaImg.Stride
-	temp := make([]byte, stride)
-
-	for y := 0; y < height/2; y++ {
-		top := rgbaImg.Pix[y*stride : (y+1)*stride]
-		bottom := rgbaImg.Pix[(height-y-1)*stride : (height-y)*stride]
-		copy(temp, top)
-		copy(top, bottom)
-		copy(bottom, temp)
+	flipped := image.NewRGBA(bounds)
+	rowLength := bounds.Dx() * 4
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		from := src.PixOffset(bounds.Min.X, y)
+		to := flipped.PixOffset(bounds.Min.X, bounds.Max.Y-1-(y-bounds.Min.Y))
+		copy(flipped.Pix[to:to+rowLength], src.Pix[from:from+rowLength])
 	}
-
-	return rgbaImg
+	return flipped
 }
@@ -0,0 +1,25 @@
+package opengl
+
+import (
+	"image"
+	"testing"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+func TestRenderTextureRejectsIndexedHandles(t *testing.T) {
+	renderer := &Renderer{}
+	renderer.TextureManager = NewTextureManager(renderer)
+
+	// registered the way UploadIndexedTexture does, without creating GL textures
+	textureID := renderer.newTextureID()
+	renderer.textureBounds[textureID] = image.Rect(0, 0, 8, 8)
+	renderer.indexed[textureID] = &IndexedTexture{Width: 8, Height: 8}
+
+	renderer.RenderTexture(textureID, &graphics.TextureRenderOptions{Scale: 1})
+	renderer.RenderNineSlice(textureID, graphics.Insets{}, &graphics.NineSliceRenderOptions{Width: 8, Height: 8})
+	renderer.RenderTiledTexture(textureID, &graphics.TiledTextureRenderOptions{Width: 8, Height: 8})
+	if len(renderer.Vertices) != 0 {
+		t.Errorf("drawing an indexed handle queued %d vertices, want none", len(renderer.Vertices))
+	}
+}
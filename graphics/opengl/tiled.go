@@ -0,0 +1,63 @@
+package opengl
+
+import (
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// RenderTiledTexture fills the destination rectangle with repeated copies of a texture region.
+// Wrapping happens in the shader against the region's rectangle in the atlas,
+// so the region does not need to be a power of two or live in its own texture.
+func (renderer *Renderer) RenderTiledTexture(textureID uint32, options *graphics.TiledTextureRenderOptions) {
+	region, exists := renderer.TextureManager.region(textureID)
+	if !exists {
+		diag.Errorf("Texture handle not found")
+		return
+	}
+
+	bounds := region.bounds
+	rectWidth, rectHeight := options.RectWidth, options.RectHeight
+	if rectWidth == 0 || rectHeight == 0 {
+		rectWidth, rectHeight = float32(bounds.Dx()), float32(bounds.Dy())
+	}
+
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	tileWidth := rectWidth * scale
+	tileHeight := rectHeight * scale
+
+	atlasWidth := float32(region.width)
+	atlasHeight := float32(region.height)
+	rectX, rectY := atlasSubRect(bounds, options.RectX, options.RectY, rectWidth, rectHeight)
+
+	// inset by half a texel so the edges of the region never sample its neighbors in the atlas
+	texRect := [4]float32{
+		(rectX + 0.5) / atlasWidth,
+		(rectY + rectHeight - 0.5) / atlasHeight,
+		(rectX + rectWidth - 0.5) / atlasWidth,
+		(rectY + 0.5) / atlasHeight,
+	}
+
+	offsetX := options.OffsetX / rectWidth
+	offsetY := options.OffsetY / rectHeight
+
+	renderer.useTexture(region.glTextureID)
+	renderer.appendQuad(
+		&graphics.TextureRenderOptions{X: options.X, Y: options.Y},
+		options.Width,
+		options.Height,
+		[2]float32{offsetX, offsetY},
+		[2]float32{offsetX + options.Width/tileWidth, offsetY + options.Height/tileHeight},
+		graphics.Vertex{
+			OpCode: graphics.OP_CODE_TEXTURE_TILED,
+			Color: textureColor(&graphics.TextureRenderOptions{
+				Tint:  options.Tint,
+				Alpha: options.Alpha,
+			}),
+			TexRect:  texRect,
+			WrapMode: float32(options.WrapMode),
+		},
+	)
+}
@@ -0,0 +1,190 @@
+// Package software is a graphics backend that rasterizes the banana draw API on the CPU
+// into any fb.Displayer, such as a TinyGo display driver or an in-memory fb.ImageFB.
+//
+// Only the parts of the frame that changed are sent to the display. Shapes, textures, nine-slices,
+// tiled textures, text and framebuffers are drawn; shaders and custom shapes need a GPU and are not supported.
+// Programs that only use this backend can build with the nogl tag to leave OpenGL and cgo out.
+package software
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	"github.com/dfirebaugh/banana/assets"
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/dfirebaugh/banana/graphics/font"
+	"github.com/dfirebaugh/banana/pkg/fb"
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+// ErrUnsupported is returned for features that need a GPU.
+var ErrUnsupported = errors.New("not supported by the software backend")
+
+var _ graphics.GraphicsBackend = (*Backend)(nil)
+
+type Backend struct {
+	display fb.Displayer
+	// screen is the frame being drawn and front is what the display currently shows.
+	screen *surface
+	front  *image.RGBA
+	// current is the surface being drawn into. targets are the surfaces bound before it.
+	current *surface
+	targets []*surface
+	dirty   []image.Rectangle
+
+	textures      map[uint32]*image.RGBA
+	indexed       map[uint32]*indexedTexture
+	nextTextureID uint32
+	font          *font.Font
+	fontImage     *image.RGBA
+
+	events     chan input.Event
+	inputFn    func(eventChan chan input.Event)
+	title      string
+	isDisposed bool
+	warned     map[string]bool
+}
+
+// New creates a backend that draws into the whole display.
+func New(display fb.Displayer) *Backend {
+	width, height := display.Size()
+	screen := newSurface(int(width), int(height))
+	b := &Backend{
+		display:  display,
+		screen:   screen,
+		front:    image.NewRGBA(screen.img.Rect),
+		current:  screen,
+		textures: make(map[uint32]*image.RGBA),
+		indexed:  make(map[uint32]*indexedTexture),
+		events:   make(chan input.Event, 100),
+		warned:   make(map[string]bool),
+	}
+	b.Init()
+	return b
+}
+
+// Init loads the default font.
+func (b *Backend) Init() {
+	if _, err := b.LoadFont(assets.LatoRegular); err != nil {
+		diag.Errorf("Failed to load the default font: %v", err)
+	}
+}
+
+// SendEvent delivers an input event, such as a button read from a GPIO pin, to the engine.
+func (b *Backend) SendEvent(evt input.Event) {
+	if b.inputFn == nil {
+		return
+	}
+	b.events <- evt
+	b.inputFn(b.events)
+}
+
+func (b *Backend) SetInputCallback(fn func(eventChan chan input.Event)) {
+	b.inputFn = fn
+}
+
+// PollEvents reports whether the engine should keep running. Input arrives through SendEvent.
+func (b *Backend) PollEvents() bool {
+	return !b.isDisposed
+}
+
+// Clear fills the current target.
+func (b *Backend) Clear(c color.Color) {
+	b.current.clear(c)
+}
+
+// Draw does nothing. Everything is rasterized as soon as it is queued.
+func (b *Backend) Draw() {}
+
+// SwapBuffers sends the parts of the frame that changed to the display.
+func (b *Backend) SwapBuffers() {
+	b.present()
+}
+
+func (b *Backend) Begin() {}
+
+func (b *Backend) End() {}
+
+// GetViewportSize returns the size of the current target.
+func (b *Backend) GetViewportSize() (int, int) {
+	return b.current.size()
+}
+
+func (b *Backend) Close() {
+	b.isDisposed = true
+}
+
+func (b *Backend) NewShader(vertexSource, fragmentSource string) (graphics.Shader, error) {
+	return nil, ErrUnsupported
+}
+
+func (b *Backend) NewShaderFromFiles(vertexPath, fragmentPath string) (graphics.Shader, error) {
+	return nil, ErrUnsupported
+}
+
+func (b *Backend) EnableShaderHotReload(dir string) error {
+	return ErrUnsupported
+}
+
+func (b *Backend) RegisterShape(name string, sdfSource string) (graphics.OpCode, error) {
+	return 0, ErrUnsupported
+}
+
+func (b *Backend) SetPostProcess(shaders []graphics.Shader) {
+	if len(shaders) > 0 {
+		b.warnOnce("post process", "The software backend can not post process")
+	}
+}
+
+// SetDebugOutput does nothing, there is no driver to report from.
+func (b *Backend) SetDebugOutput(enabled bool) {}
+
+// The display has a fixed size and no window, so the window methods only keep track of what they were given.
+
+func (b *Backend) DisableWindowResize() {}
+
+func (b *Backend) SetFullScreenBorderless(v bool) {}
+
+func (b *Backend) SetBorderlessWindowed(v bool) {}
+
+func (b *Backend) SetWindowTitle(title string) {
+	b.title = title
+}
+
+func (b *Backend) DestroyWindow() {
+	b.isDisposed = true
+}
+
+func (b *Backend) SetWindowSize(width int, height int) {
+	w, h := b.screen.size()
+	if width != w || height != h {
+		b.warnOnce("window size", "The display is %dx%d and can not be resized to %dx%d", w, h, width, height)
+	}
+}
+
+func (b *Backend) GetWindowSize() (int, int) {
+	return b.screen.size()
+}
+
+func (b *Backend) GetWindowPosition() (x int, y int) {
+	return 0, 0
+}
+
+func (b *Backend) SetWindowPosition(x, y int) {}
+
+func (b *Backend) SetResizedCallback(fn func(physicalWidth, physicalHeight uint32)) {}
+
+func (b *Backend) ShouldClose() bool {
+	return b.isDisposed
+}
+
+func (b *Backend) IsDisposed() bool {
+	return b.isDisposed
+}
+
+// logDisplayError reports a failed transfer to the display.
+func logDisplayError(err error) {
+	diag.Errorf("Failed to update the display: %v", err)
+}
@@ -0,0 +1,177 @@
+package software
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/pkg/fb"
+)
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+)
+
+// rect builds the vertices of a rounded rectangle the way banana.Rect does.
+type rect struct {
+	x, y, width, height, radius float32
+	color, stroke               color.RGBA
+	strokeWidth                 float32
+}
+
+func (r rect) GetVertices(screenWidth, screenHeight int) []graphics.Vertex {
+	halfWidth, halfHeight := r.width*0.5, r.height*0.5
+	normX := (r.x/float32(screenWidth))*2 - 1
+	normY := 1 - (r.y/float32(screenHeight))*2
+	corners := [][2]float32{
+		{-halfWidth, halfHeight}, {-halfWidth, -halfHeight}, {halfWidth, -halfHeight},
+		{-halfWidth, halfHeight}, {halfWidth, -halfHeight}, {halfWidth, halfHeight},
+	}
+	var vertices []graphics.Vertex
+	for _, corner := range corners {
+		vertices = append(vertices, graphics.Vertex{
+			ShapePos:    [2]float32{normX + halfWidth/float32(screenWidth)*2, normY - halfHeight/float32(screenHeight)*2},
+			LocalPos:    corner,
+			OpCode:      graphics.OP_CODE_RECT,
+			Radius:      r.radius,
+			Width:       r.width,
+			Height:      r.height,
+			Color:       toVec4(r.color),
+			Resolution:  [2]float32{float32(screenWidth), float32(screenHeight)},
+			StrokeWidth: r.strokeWidth,
+			StrokeColor: toVec4(r.stroke),
+		})
+	}
+	return vertices
+}
+
+func newTestBackend(width, height int) (*Backend, *fb.ImageFB) {
+	display := fb.New(width, height)
+	return New(display), display
+}
+
+func expectPixel(t *testing.T, img *image.RGBA, x, y int, want color.RGBA) {
+	t.Helper()
+	if got := img.RGBAAt(x, y); got != want {
+		t.Errorf("pixel %d,%d = %v, want %v", x, y, got, want)
+	}
+}
+
+func TestClearSendsWholeScreen(t *testing.T) {
+	b, display := newTestBackend(64, 48)
+	b.Clear(red)
+	b.SwapBuffers()
+
+	want := []image.Rectangle{
+		image.Rect(0, 0, 64, 16),
+		image.Rect(0, 16, 64, 32),
+		image.Rect(0, 32, 64, 48),
+	}
+	if got := b.DirtyRects(); !equalRects(got, want) {
+		t.Fatalf("DirtyRects() = %v, want %v", got, want)
+	}
+	img := display.ToImage()
+	expectPixel(t, img, 0, 0, red)
+	expectPixel(t, img, 63, 47, red)
+
+	b.Clear(red)
+	b.SwapBuffers()
+	if got := b.DirtyRects(); len(got) != 0 {
+		t.Errorf("DirtyRects() after an unchanged frame = %v, want none", got)
+	}
+}
+
+func TestRectOnlySendsChangedTiles(t *testing.T) {
+	b, display := newTestBackend(64, 64)
+	b.Clear(black)
+	b.SwapBuffers()
+	display.ClearDirty()
+
+	b.Clear(black)
+	b.Render(rect{x: 20, y: 20, width: 8, height: 8, color: red})
+	b.SwapBuffers()
+
+	want := []image.Rectangle{image.Rect(16, 16, 32, 32)}
+	if got := b.DirtyRects(); !equalRects(got, want) {
+		t.Fatalf("DirtyRects() = %v, want %v", got, want)
+	}
+	img := display.ToImage()
+	expectPixel(t, img, 24, 24, red)
+	expectPixel(t, img, 18, 24, black)
+	expectPixel(t, img, 30, 24, black)
+	if dirty := display.Dirty(); !dirty.In(image.Rect(16, 16, 32, 32)) {
+		t.Errorf("display was written at %v, outside of the changed tile", dirty)
+	}
+}
+
+func TestStrokedRectKeepsItsFill(t *testing.T) {
+	b, _ := newTestBackend(64, 64)
+	b.Clear(black)
+	b.Render(rect{x: 12, y: 12, width: 40, height: 40, radius: 1, color: red, stroke: blue, strokeWidth: 4})
+
+	img := b.ReadScreen()
+	expectPixel(t, img, 32, 32, red)
+	expectPixel(t, img, 20, 32, red)
+	expectPixel(t, img, 13, 32, blue)
+	expectPixel(t, img, 32, 50, blue)
+	expectPixel(t, img, 10, 32, black)
+}
+
+func TestRenderTexture(t *testing.T) {
+	b, display := newTestBackend(32, 32)
+	texture := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	texture.SetRGBA(0, 0, red)
+	texture.SetRGBA(1, 0, green)
+	texture.SetRGBA(0, 1, blue)
+	texture.SetRGBA(1, 1, black)
+	id := b.UploadTexture(texture)
+
+	b.Clear(color.White)
+	b.RenderTexture(id, &graphics.TextureRenderOptions{X: 8, Y: 8, Scale: 4})
+	b.SwapBuffers()
+
+	img := display.ToImage()
+	expectPixel(t, img, 9, 9, red)
+	expectPixel(t, img, 14, 9, green)
+	expectPixel(t, img, 9, 14, blue)
+	expectPixel(t, img, 14, 14, black)
+	expectPixel(t, img, 17, 9, color.RGBA{255, 255, 255, 255})
+}
+
+func TestRenderTextureAlpha(t *testing.T) {
+	b, _ := newTestBackend(32, 32)
+	texture := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range texture.Pix {
+		texture.Pix[i] = 255
+	}
+	id := b.UploadTexture(texture)
+
+	transparent, half := float32(0), float32(0.5)
+	b.Clear(black)
+	b.RenderTexture(id, &graphics.TextureRenderOptions{X: 0, Y: 0, Scale: 1, Alpha: &transparent})
+	b.RenderTexture(id, &graphics.TextureRenderOptions{X: 8, Y: 0, Scale: 1, Alpha: &half})
+	b.RenderTexture(id, &graphics.TextureRenderOptions{X: 16, Y: 0, Scale: 1})
+
+	img := b.ReadScreen()
+	expectPixel(t, img, 1, 1, black)
+	if got := img.RGBAAt(9, 1); got.R < 120 || got.R > 135 {
+		t.Errorf("pixel at half alpha = %v, want half way to white", got)
+	}
+	expectPixel(t, img, 17, 1, color.RGBA{255, 255, 255, 255})
+}
+
+func equalRects(a, b []image.Rectangle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package software
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+// Framebuffer is an offscreen image that can be drawn into and then drawn like a texture.
+type Framebuffer struct {
+	textureID uint32
+	surface   *surface
+	backend   *Backend
+}
+
+// AddFramebuffer creates a framebuffer. Its texture handle works with RenderTexture.
+func (b *Backend) AddFramebuffer(width, height int) (graphics.Framebuffer, error) {
+	fb := &Framebuffer{surface: newSurface(width, height), backend: b}
+	b.nextTextureID++
+	fb.textureID = b.nextTextureID
+	b.textures[fb.textureID] = fb.surface.img
+	return fb, nil
+}
+
+// AddFramebufferWithOptions creates a framebuffer. Every framebuffer stores 8 bit RGBA without multisampling,
+// so the options are ignored.
+func (b *Backend) AddFramebufferWithOptions(width, height int, options graphics.FramebufferOptions) (graphics.Framebuffer, error) {
+	return b.AddFramebuffer(width, height)
+}
+
+// BindFramebuffer draws into a framebuffer until it is unbound. Binding nil returns to the screen.
+func (b *Backend) BindFramebuffer(fb graphics.Framebuffer) {
+	if fb == nil {
+		b.targets = b.targets[:0]
+		b.current = b.screen
+		return
+	}
+	target, ok := fb.(*Framebuffer)
+	if !ok {
+		b.warnOnce("framebuffer type", "Can not bind framebuffer of type %T", fb)
+		return
+	}
+	b.targets = append(b.targets, b.current)
+	b.current = target.surface
+}
+
+// UnbindFramebuffer returns to the target that was bound before the framebuffer.
+func (b *Backend) UnbindFramebuffer() {
+	n := len(b.targets)
+	if n == 0 {
+		b.current = b.screen
+		return
+	}
+	b.current = b.targets[n-1]
+	b.targets = b.targets[:n-1]
+}
+
+func (fb *Framebuffer) GetID() uint32 {
+	return fb.textureID
+}
+
+func (fb *Framebuffer) GetTextureID() uint32 {
+	return fb.textureID
+}
+
+func (fb *Framebuffer) GetWidth() int {
+	return fb.surface.img.Rect.Dx()
+}
+
+func (fb *Framebuffer) GetHeight() int {
+	return fb.surface.img.Rect.Dy()
+}
+
+func (fb *Framebuffer) Bind() {
+	fb.backend.BindFramebuffer(fb)
+}
+
+func (fb *Framebuffer) Unbind() {
+	fb.backend.UnbindFramebuffer()
+}
+
+func (fb *Framebuffer) Clear(c color.Color) {
+	fb.surface.clear(c)
+}
+
+// Resize replaces the contents with a transparent image of the new size.
+func (fb *Framebuffer) Resize(width, height int) {
+	fb.surface.img = image.NewRGBA(image.Rect(0, 0, width, height))
+	fb.backend.textures[fb.textureID] = fb.surface.img
+}
+
+func (fb *Framebuffer) Destroy() {
+	delete(fb.backend.textures, fb.textureID)
+}
+
+// Draw draws the framebuffer into the target it was bound from, or the current target when it is not bound.
+func (fb *Framebuffer) Draw(x, y, width, height int) {
+	b := fb.backend
+	current := b.current
+	if current == fb.surface && len(b.targets) > 0 {
+		b.current = b.targets[len(b.targets)-1]
+	}
+	b.renderTexture(fb.surface.img, &graphics.TextureRenderOptions{
+		X:             float32(x),
+		Y:             float32(y),
+		DesiredWidth:  float32(width),
+		DesiredHeight: float32(height),
+	})
+	b.current = current
+}
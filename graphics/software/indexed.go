@@ -0,0 +1,80 @@
+package software
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// indexedTexture keeps the indices and palette of an indexed texture and the colors they resolve to.
+// The colors are resolved again the next time it is drawn after either changed.
+type indexedTexture struct {
+	pix      *image.Paletted
+	resolved *image.RGBA
+	stale    bool
+}
+
+func (b *Backend) UploadIndexedTexture(img *image.Paletted) uint32 {
+	bounds := img.Bounds()
+	rect := image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+	pix := image.NewPaletted(rect, append(color.Palette(nil), img.Palette...))
+	draw.Draw(pix, rect, img, bounds.Min, draw.Src)
+
+	b.nextTextureID++
+	b.indexed[b.nextTextureID] = &indexedTexture{pix: pix, resolved: image.NewRGBA(rect), stale: true}
+	return b.nextTextureID
+}
+
+func (b *Backend) UpdateIndexedTexture(textureID uint32, img *image.Paletted, xOffset, yOffset int) {
+	t, ok := b.indexed[textureID]
+	if !ok {
+		diag.Errorf("Indexed texture ID %d not found", textureID)
+		return
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			t.pix.SetColorIndex(xOffset+x-bounds.Min.X, yOffset+y-bounds.Min.Y, img.ColorIndexAt(x, y))
+		}
+	}
+	t.stale = true
+}
+
+func (b *Backend) UpdatePalette(textureID uint32, palette color.Palette) {
+	t, ok := b.indexed[textureID]
+	if !ok {
+		diag.Errorf("Indexed texture ID %d not found", textureID)
+		return
+	}
+	t.pix.Palette = append(t.pix.Palette[:0], palette...)
+	t.stale = true
+}
+
+func (b *Backend) RenderIndexedTexture(textureID uint32, options *graphics.TextureRenderOptions) {
+	t, ok := b.indexed[textureID]
+	if !ok {
+		diag.Errorf("Indexed texture handle not found")
+		return
+	}
+	if t.stale {
+		t.resolve()
+	}
+	b.renderTexture(t.resolved, options)
+}
+
+// resolve looks every index up in the palette. Indices past the end of the palette are transparent.
+func (t *indexedTexture) resolve() {
+	var lookup [256]color.RGBA
+	for i, c := range t.pix.Palette[:min(len(t.pix.Palette), len(lookup))] {
+		lookup[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	for i, index := range t.pix.Pix {
+		c := lookup[index]
+		p := t.resolved.Pix[i*4 : i*4+4 : i*4+4]
+		p[0], p[1], p[2], p[3] = c.R, c.G, c.B, c.A
+	}
+	t.stale = false
+}
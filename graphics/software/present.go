@@ -0,0 +1,89 @@
+package software
+
+import (
+	"image"
+)
+
+// tileSize is the edge length of the squares the screen is compared in.
+// Small tiles send fewer unchanged pixels, large tiles produce fewer rectangles.
+const tileSize = 16
+
+// ReadScreen returns a copy of the frame.
+func (b *Backend) ReadScreen() *image.RGBA {
+	img := image.NewRGBA(b.screen.img.Rect)
+	copy(img.Pix, b.screen.img.Pix)
+	return img
+}
+
+// DirtyRects returns the rectangles that were sent to the display by the last SwapBuffers.
+func (b *Backend) DirtyRects() []image.Rectangle {
+	return b.dirty
+}
+
+// present compares the tiles drawn into since the last frame with what the display shows
+// and sends the ones that changed. Runs of changed tiles in a row of tiles are sent as one rectangle.
+func (b *Backend) present() {
+	b.dirty = b.dirty[:0]
+	touched := b.screen.touched
+	b.screen.touched = image.Rectangle{}
+	if touched.Empty() {
+		return
+	}
+
+	bounds := b.screen.img.Rect
+	startX := touched.Min.X / tileSize * tileSize
+	startY := touched.Min.Y / tileSize * tileSize
+	for y := startY; y < touched.Max.Y; y += tileSize {
+		run := image.Rectangle{}
+		for x := startX; x < touched.Max.X; x += tileSize {
+			tile := image.Rect(x, y, x+tileSize, y+tileSize).Intersect(bounds)
+			if !b.changed(tile) {
+				if !run.Empty() {
+					b.dirty = append(b.dirty, run)
+					run = image.Rectangle{}
+				}
+				continue
+			}
+			run = run.Union(tile)
+		}
+		if !run.Empty() {
+			b.dirty = append(b.dirty, run)
+		}
+	}
+
+	for _, r := range b.dirty {
+		b.send(r)
+	}
+	if len(b.dirty) > 0 {
+		if err := b.display.Display(); err != nil {
+			logDisplayError(err)
+		}
+	}
+}
+
+// changed reports whether a rectangle of the frame differs from what the display shows.
+func (b *Backend) changed(r image.Rectangle) bool {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		start := b.screen.img.PixOffset(r.Min.X, y)
+		end := start + r.Dx()*4
+		back := b.screen.img.Pix[start:end]
+		front := b.front.Pix[start:end]
+		if string(back) != string(front) {
+			return true
+		}
+	}
+	return false
+}
+
+// send writes a rectangle of the frame to the display and remembers it as shown.
+func (b *Backend) send(r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := b.screen.img.RGBAAt(x, y)
+			b.display.SetPixel(int16(x), int16(y), c)
+		}
+		start := b.screen.img.PixOffset(r.Min.X, y)
+		end := start + r.Dx()*4
+		copy(b.front.Pix[start:end], b.screen.img.Pix[start:end])
+	}
+}
@@ -0,0 +1,160 @@
+package software
+
+import (
+	"image"
+	"image/color"
+)
+
+// subpixel is the number of fixed point steps per pixel vertices are snapped to.
+// Snapping makes the edge functions exact, so triangles that share an edge never both cover a pixel on it.
+const subpixel = 16
+
+// surface is an image that is drawn into. touched grows to cover everything drawn since it was last reset.
+type surface struct {
+	img     *image.RGBA
+	touched image.Rectangle
+}
+
+func newSurface(width, height int) *surface {
+	return &surface{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (s *surface) size() (int, int) {
+	return s.img.Rect.Dx(), s.img.Rect.Dy()
+}
+
+func (s *surface) touch(r image.Rectangle) {
+	s.touched = s.touched.Union(r.Intersect(s.img.Rect))
+}
+
+func (s *surface) clear(c color.Color) {
+	r, g, b, a := c.RGBA()
+	px := [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	for i := 0; i < len(s.img.Pix); i += 4 {
+		copy(s.img.Pix[i:i+4], px[:])
+	}
+	s.touch(s.img.Rect)
+}
+
+// blend composites a premultiplied color over a pixel.
+func (s *surface) blend(x, y int, c [4]float32) {
+	if c[3] <= 0 {
+		return
+	}
+	i := s.img.PixOffset(x, y)
+	pix := s.img.Pix[i : i+4 : i+4]
+	inv := 1 - c[3]
+	for ch := 0; ch < 4; ch++ {
+		v := c[ch]*255 + float32(pix[ch])*inv
+		pix[ch] = uint8(min(max(v+0.5, 0), 255))
+	}
+}
+
+// point is a triangle corner in pixels with the attributes interpolated across the triangle.
+type point struct {
+	x, y float32
+	// local is the position relative to the shape, the way the vertex shader passes local_pos.
+	local [2]float32
+	// uv is a texture coordinate in texels.
+	uv [2]float32
+}
+
+type fixedPoint struct{ x, y int64 }
+
+func snap(p point) fixedPoint {
+	return fixedPoint{int64(p.x*subpixel + 0.5), int64(p.y*subpixel + 0.5)}
+}
+
+func edge(a, b, p fixedPoint) int64 {
+	return (b.x-a.x)*(p.y-a.y) - (b.y-a.y)*(p.x-a.x)
+}
+
+// topLeft reports whether an edge of a clockwise triangle is a top or a left edge.
+// Pixel centers exactly on an edge are only covered by the triangle whose top or left edge it is.
+func topLeft(a, b fixedPoint) bool {
+	dx, dy := b.x-a.x, b.y-a.y
+	return dy < 0 || (dy == 0 && dx > 0)
+}
+
+// fillTriangle calls shade for every pixel whose center is inside the triangle and blends the premultiplied color it returns.
+func (s *surface) fillTriangle(a, b, c point, shade func(p point) [4]float32) {
+	fa, fb, fc := snap(a), snap(b), snap(c)
+	area := edge(fa, fb, fc)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		b, c = c, b
+		fb, fc = fc, fb
+		area = -area
+	}
+
+	bounds := image.Rect(
+		int(min(fa.x, fb.x, fc.x)/subpixel), int(min(fa.y, fb.y, fc.y)/subpixel),
+		int(max(fa.x, fb.x, fc.x)/subpixel)+1, int(max(fa.y, fb.y, fc.y)/subpixel)+1,
+	).Intersect(s.img.Rect)
+	if bounds.Empty() {
+		return
+	}
+	s.touch(bounds)
+
+	biasA, biasB, biasC := int64(0), int64(0), int64(0)
+	if !topLeft(fb, fc) {
+		biasA = -1
+	}
+	if !topLeft(fc, fa) {
+		biasB = -1
+	}
+	if !topLeft(fa, fb) {
+		biasC = -1
+	}
+
+	inv := 1 / float32(area)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			center := fixedPoint{int64(x)*subpixel + subpixel/2, int64(y)*subpixel + subpixel/2}
+			wa := edge(fb, fc, center)
+			wb := edge(fc, fa, center)
+			wc := edge(fa, fb, center)
+			if wa+biasA < 0 || wb+biasB < 0 || wc+biasC < 0 {
+				continue
+			}
+			la, lb, lc := float32(wa)*inv, float32(wb)*inv, float32(wc)*inv
+			p := point{
+				x: float32(x) + 0.5,
+				y: float32(y) + 0.5,
+				local: [2]float32{
+					a.local[0]*la + b.local[0]*lb + c.local[0]*lc,
+					a.local[1]*la + b.local[1]*lb + c.local[1]*lc,
+				},
+				uv: [2]float32{
+					a.uv[0]*la + b.uv[0]*lb + c.uv[0]*lc,
+					a.uv[1]*la + b.uv[1]*lb + c.uv[1]*lc,
+				},
+			}
+			s.blend(x, y, shade(p))
+		}
+	}
+}
+
+// fillQuad fills the quad a, b, c, d given in order around its edge.
+func (s *surface) fillQuad(a, b, c, d point, shade func(p point) [4]float32) {
+	s.fillTriangle(a, b, c, shade)
+	s.fillTriangle(a, c, d, shade)
+}
+
+func premultiply(c [4]float32) [4]float32 {
+	return [4]float32{c[0] * c[3], c[1] * c[3], c[2] * c[3], c[3]}
+}
+
+func toVec4(c color.Color) [4]float32 {
+	if c == nil {
+		return [4]float32{}
+	}
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return [4]float32{}
+	}
+	// color.Color is premultiplied, the vertex colors of the draw API are not
+	return [4]float32{float32(r) / float32(a), float32(g) / float32(a), float32(b) / float32(a), float32(a) / 0xffff}
+}
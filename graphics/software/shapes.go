@@ -0,0 +1,107 @@
+package software
+
+import (
+	"math"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// Render rasterizes the triangles of a shape, shading them the way the OpenGL backend's uber shader does.
+func (b *Backend) Render(shape graphics.Renderable) {
+	width, height := b.GetViewportSize()
+	vertices := shape.GetVertices(width, height)
+	for i := 0; i+2 < len(vertices); i += 3 {
+		b.renderTriangle(vertices[i : i+3])
+	}
+}
+
+func (b *Backend) renderTriangle(vertices []graphics.Vertex) {
+	v := vertices[0]
+	var shade func(p point) [4]float32
+	switch v.OpCode {
+	case graphics.OP_CODE_VERTEX:
+		c := premultiply(v.Color)
+		shade = func(point) [4]float32 { return c }
+	case graphics.OP_CODE_CIRCLE:
+		shade = func(p point) [4]float32 {
+			return shadeSDF(length(p.local[0], p.local[1])-v.Radius, v)
+		}
+	case graphics.OP_CODE_RECT:
+		shade = func(p point) [4]float32 {
+			return shadeSDF(sdRoundedRect(p.local, v.Width*0.5, v.Height*0.5, v.Radius), v)
+		}
+	default:
+		b.warnOnce("op code", "The software backend can not draw shapes with op code %v, custom shapes need a GPU", v.OpCode)
+		return
+	}
+
+	var corners [3]point
+	for i, vertex := range vertices {
+		corners[i] = b.toPoint(vertex)
+	}
+	b.current.fillTriangle(corners[0], corners[1], corners[2], shade)
+}
+
+// toPoint places a vertex in pixels the way the vertex shader places it in clip space.
+func (b *Backend) toPoint(v graphics.Vertex) point {
+	width, height := b.current.size()
+	ndc := v.FsQuadPos
+	switch v.OpCode {
+	case graphics.OP_CODE_TEXT, graphics.OP_CODE_TEXTURE, graphics.OP_CODE_TEXTURE_TILED:
+	default:
+		ndc = [2]float32{
+			v.ShapePos[0] + v.LocalPos[0]/v.Resolution[0]*2,
+			v.ShapePos[1] + v.LocalPos[1]/v.Resolution[1]*2,
+		}
+	}
+	return point{
+		x:     (ndc[0] + 1) * 0.5 * float32(width),
+		y:     (1 - ndc[1]) * 0.5 * float32(height),
+		local: v.LocalPos,
+	}
+}
+
+// shadeSDF colors a pixel from its signed distance to a shape's edge, with the edge anti-aliased over a pixel
+// and the stroke drawn along the inside of the edge.
+func shadeSDF(d float32, v graphics.Vertex) [4]float32 {
+	coverage := clamp01(0.5 - d)
+	if coverage == 0 {
+		return [4]float32{}
+	}
+	c := v.Color
+	if v.StrokeWidth > 0 {
+		fill := clamp01(0.5 - (d + v.StrokeWidth))
+		for i := range c {
+			c[i] = v.StrokeColor[i] + (v.Color[i]-v.StrokeColor[i])*fill
+		}
+	}
+	c[3] *= coverage
+	return premultiply(c)
+}
+
+func sdRoundedRect(p [2]float32, halfWidth, halfHeight, radius float32) float32 {
+	qx := abs(p[0]) - (halfWidth - radius)
+	qy := abs(p[1]) - (halfHeight - radius)
+	return length(max(qx, 0), max(qy, 0)) + min(max(qx, qy), 0) - radius
+}
+
+func length(x, y float32) float32 {
+	return float32(math.Sqrt(float64(x*x + y*y)))
+}
+
+func abs(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
+
+func clamp01(v float32) float32 {
+	return min(max(v, 0), 1)
+}
+
+func (b *Backend) warnOnce(key string, format string, args ...any) {
+	if b.warned[key] {
+		return
+	}
+	b.warned[key] = true
+	diag.Warnf(format, args...)
+}
@@ -0,0 +1,299 @@
+package software
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/dfirebaugh/banana/graphics/font"
+)
+
+// UploadTexture copies an image and returns a handle to draw it with.
+func (b *Backend) UploadTexture(img image.Image) uint32 {
+	bounds := img.Bounds()
+	texture := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(texture, texture.Bounds(), img, bounds.Min, draw.Src)
+	b.nextTextureID++
+	b.textures[b.nextTextureID] = texture
+	return b.nextTextureID
+}
+
+// UploadTextureWithOptions uploads an image. Textures are always sampled nearest, so the options are ignored.
+func (b *Backend) UploadTextureWithOptions(img image.Image, options graphics.TextureOptions) uint32 {
+	return b.UploadTexture(img)
+}
+
+func (b *Backend) UpdateTexture(textureID uint32, img image.Image, xOffset, yOffset int) {
+	texture, ok := b.textures[textureID]
+	if !ok {
+		diag.Errorf("Texture ID not found")
+		return
+	}
+	bounds := img.Bounds()
+	dst := image.Rect(xOffset, yOffset, xOffset+bounds.Dx(), yOffset+bounds.Dy())
+	draw.Draw(texture, dst, img, bounds.Min, draw.Src)
+}
+
+func (b *Backend) DeleteTexture(textureID uint32) {
+	delete(b.textures, textureID)
+	delete(b.indexed, textureID)
+}
+
+// CompactAtlas does nothing. Every texture has its own image.
+func (b *Backend) CompactAtlas() {}
+
+func (b *Backend) RenderTexture(textureID uint32, options *graphics.TextureRenderOptions) {
+	texture, ok := b.textures[textureID]
+	if !ok {
+		diag.Errorf("Texture handle not found")
+		return
+	}
+	b.renderTexture(texture, options)
+}
+
+func (b *Backend) RenderFramebuffer(fb graphics.Framebuffer, options *graphics.TextureRenderOptions) {
+	b.RenderTexture(fb.GetTextureID(), options)
+}
+
+// renderTexture draws a region of a texture measured from its top-left corner,
+// positioned, skewed and rotated the same way as on the OpenGL backend.
+func (b *Backend) renderTexture(texture *image.RGBA, options *graphics.TextureRenderOptions) {
+	rectWidth, rectHeight := options.RectWidth, options.RectHeight
+	if rectWidth == 0 || rectHeight == 0 {
+		rectWidth, rectHeight = float32(texture.Rect.Dx()), float32(texture.Rect.Dy())
+	}
+	width := options.DesiredWidth
+	if width == 0 {
+		width = rectWidth * options.Scale
+	}
+	height := options.DesiredHeight
+	if height == 0 {
+		height = rectHeight * options.Scale
+	}
+
+	u0, u1 := options.RectX, options.RectX+rectWidth
+	v0, v1 := options.RectY, options.RectY+rectHeight
+	if options.FlipX {
+		u0, u1 = u1, u0
+	}
+	if options.FlipY {
+		v0, v1 = v1, v0
+	}
+
+	region := image.Rect(int(options.RectX), int(options.RectY), int(options.RectX+rectWidth), int(options.RectY+rectHeight)).Intersect(texture.Rect)
+	tint := premultiply(textureColor(options.Tint, options.Alpha))
+	corners := quadCorners(options, width, height, [2]float32{u0, v0}, [2]float32{u1, v1})
+	b.current.fillQuad(corners[0], corners[1], corners[2], corners[3], func(p point) [4]float32 {
+		return tinted(sample(texture, region, p.uv[0], p.uv[1]), tint)
+	})
+}
+
+// quadCorners returns the corners of a quad of the given size clockwise from its top-left corner.
+func quadCorners(options *graphics.TextureRenderOptions, width, height float32, uvTopLeft, uvBottomRight [2]float32) [4]point {
+	originX := options.OriginX * width
+	originY := options.OriginY * height
+	left, top := -originX, -originY
+	right, bottom := width-originX, height-originY
+
+	skewX := float32(math.Tan(float64(options.SkewX)))
+	skewY := float32(math.Tan(float64(options.SkewY)))
+	cosTheta := float32(math.Cos(float64(options.Rotation)))
+	sinTheta := float32(math.Sin(float64(options.Rotation)))
+
+	corners := [4][2]float32{{left, top}, {right, top}, {right, bottom}, {left, bottom}}
+	uvs := [4][2]float32{
+		uvTopLeft,
+		{uvBottomRight[0], uvTopLeft[1]},
+		uvBottomRight,
+		{uvTopLeft[0], uvBottomRight[1]},
+	}
+
+	var points [4]point
+	for i, corner := range corners {
+		// rotate with y pointing up, like the OpenGL backend, so angles turn the same way on both
+		localX := corner[0] + corner[1]*skewX
+		localY := -(corner[1] + corner[0]*skewY)
+		rotatedX := localX*cosTheta - localY*sinTheta
+		rotatedY := localX*sinTheta + localY*cosTheta
+		points[i] = point{
+			x:  options.X + rotatedX,
+			y:  options.Y - rotatedY,
+			uv: uvs[i],
+		}
+	}
+	return points
+}
+
+// sample returns the premultiplied texel under a texture coordinate, clamped to a region.
+func sample(texture *image.RGBA, region image.Rectangle, u, v float32) [4]float32 {
+	if region.Empty() {
+		return [4]float32{}
+	}
+	x := min(max(int(math.Floor(float64(u))), region.Min.X), region.Max.X-1)
+	y := min(max(int(math.Floor(float64(v))), region.Min.Y), region.Max.Y-1)
+	i := texture.PixOffset(x, y)
+	pix := texture.Pix[i : i+4 : i+4]
+	return [4]float32{float32(pix[0]) / 255, float32(pix[1]) / 255, float32(pix[2]) / 255, float32(pix[3]) / 255}
+}
+
+func tinted(texel, tint [4]float32) [4]float32 {
+	return [4]float32{texel[0] * tint[0], texel[1] * tint[1], texel[2] * tint[2], texel[3] * tint[3]}
+}
+
+// textureColor combines a tint and alpha into the color texels are multiplied by.
+func textureColor(tint color.Color, alpha *float32) [4]float32 {
+	c := [4]float32{1, 1, 1, 1}
+	if tint != nil {
+		c = toVec4(tint)
+	}
+	if alpha != nil {
+		c[3] *= *alpha
+	}
+	return c
+}
+
+// RenderNineSlice draws a texture stretched to a rectangle while its corners keep their size.
+func (b *Backend) RenderNineSlice(textureID uint32, insets graphics.Insets, options *graphics.NineSliceRenderOptions) {
+	texture, ok := b.textures[textureID]
+	if !ok {
+		diag.Errorf("Texture handle not found")
+		return
+	}
+
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	srcWidth := float32(texture.Rect.Dx())
+	srcHeight := float32(texture.Rect.Dy())
+	srcCols := [4]float32{0, insets.Left, srcWidth - insets.Right, srcWidth}
+	srcRows := [4]float32{0, insets.Top, srcHeight - insets.Bottom, srcHeight}
+
+	left, right := fitInsets(insets.Left*scale, insets.Right*scale, options.Width)
+	top, bottom := fitInsets(insets.Top*scale, insets.Bottom*scale, options.Height)
+	dstCols := [4]float32{0, left, options.Width - right, options.Width}
+	dstRows := [4]float32{0, top, options.Height - bottom, options.Height}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			srcW := srcCols[col+1] - srcCols[col]
+			srcH := srcRows[row+1] - srcRows[row]
+			dstW := dstCols[col+1] - dstCols[col]
+			dstH := dstRows[row+1] - dstRows[row]
+			if srcW <= 0 || srcH <= 0 || dstW <= 0 || dstH <= 0 {
+				continue
+			}
+			b.renderTexture(texture, &graphics.TextureRenderOptions{
+				X:             options.X + dstCols[col],
+				Y:             options.Y + dstRows[row],
+				RectX:         srcCols[col],
+				RectY:         srcRows[row],
+				RectWidth:     srcW,
+				RectHeight:    srcH,
+				DesiredWidth:  dstW,
+				DesiredHeight: dstH,
+				Tint:          options.Tint,
+				Alpha:         options.Alpha,
+			})
+		}
+	}
+}
+
+// fitInsets shrinks a pair of insets proportionally when they do not fit in the available length.
+func fitInsets(a, b, length float32) (float32, float32) {
+	if a+b <= length || a+b == 0 {
+		return a, b
+	}
+	ratio := length / (a + b)
+	return a * ratio, b * ratio
+}
+
+// RenderTiledTexture fills a rectangle with repeated copies of a texture region.
+func (b *Backend) RenderTiledTexture(textureID uint32, options *graphics.TiledTextureRenderOptions) {
+	texture, ok := b.textures[textureID]
+	if !ok {
+		diag.Errorf("Texture handle not found")
+		return
+	}
+
+	rectWidth, rectHeight := options.RectWidth, options.RectHeight
+	if rectWidth == 0 || rectHeight == 0 {
+		rectWidth, rectHeight = float32(texture.Rect.Dx()), float32(texture.Rect.Dy())
+	}
+	scale := options.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	region := image.Rect(int(options.RectX), int(options.RectY), int(options.RectX+rectWidth), int(options.RectY+rectHeight)).Intersect(texture.Rect)
+
+	// texture coordinates are measured in tiles and wrapped into the region per pixel
+	offsetX := options.OffsetX / rectWidth
+	offsetY := options.OffsetY / rectHeight
+	tint := premultiply(textureColor(options.Tint, options.Alpha))
+	corners := quadCorners(
+		&graphics.TextureRenderOptions{X: options.X, Y: options.Y},
+		options.Width,
+		options.Height,
+		[2]float32{offsetX, offsetY},
+		[2]float32{offsetX + options.Width/(rectWidth*scale), offsetY + options.Height/(rectHeight*scale)},
+	)
+	b.current.fillQuad(corners[0], corners[1], corners[2], corners[3], func(p point) [4]float32 {
+		u := float32(region.Min.X) + wrap(p.uv[0], options.WrapMode)*float32(region.Dx())
+		v := float32(region.Min.Y) + wrap(p.uv[1], options.WrapMode)*float32(region.Dy())
+		return tinted(sample(texture, region, u, v), tint)
+	})
+}
+
+func wrap(t float32, mode graphics.WrapMode) float32 {
+	switch mode {
+	case graphics.WrapMirror:
+		m := float32(math.Mod(float64(t), 2))
+		if m < 0 {
+			m += 2
+		}
+		if m > 1 {
+			return 2 - m
+		}
+		return m
+	case graphics.WrapClamp:
+		return clamp01(t)
+	default:
+		return t - float32(math.Floor(float64(t)))
+	}
+}
+
+func (b *Backend) LoadFont(fontData []byte) (graphics.Font, error) {
+	f, err := font.LoadFont(fontData)
+	if err != nil {
+		diag.Errorf("Failed to load font: %v", err)
+		return f, err
+	}
+	b.font = f
+	b.fontImage = image.NewRGBA(f.Image().Bounds())
+	draw.Draw(b.fontImage, b.fontImage.Bounds(), f.Image(), image.Point{}, draw.Src)
+	return f, nil
+}
+
+// RenderText draws a line of text with its glyphs colored by their coverage.
+func (b *Backend) RenderText(text string, options *graphics.TextRenderOptions) {
+	if b.font == nil {
+		diag.Errorf("Font texture not found")
+		return
+	}
+	c := premultiply(toVec4(options.Color))
+	atlasWidth, atlasHeight := float32(b.fontImage.Rect.Dx()), float32(b.fontImage.Rect.Dy())
+	b.font.Layout(text, options.X, options.Y, options.Size, func(glyph *font.Glyph, x, y, width, height float32) {
+		u0, v0 := glyph.TexCoords[0]*atlasWidth, glyph.TexCoords[1]*atlasHeight
+		u1, v1 := glyph.TexCoords[2]*atlasWidth, glyph.TexCoords[3]*atlasHeight
+		region := image.Rect(int(u0), int(v0), int(u1+0.5), int(v1+0.5))
+		corners := quadCorners(&graphics.TextureRenderOptions{X: x, Y: y}, width, height, [2]float32{u0, v0}, [2]float32{u1, v1})
+		b.current.fillQuad(corners[0], corners[1], corners[2], corners[3], func(p point) [4]float32 {
+			coverage := sample(b.fontImage, region, p.uv[0], p.uv[1])[3]
+			return [4]float32{c[0] * coverage, c[1] * coverage, c[2] * coverage, c[3] * coverage}
+		})
+	})
+}
@@ -0,0 +1,183 @@
+// Package terminal is a graphics backend that draws frames into a terminal with half-block characters
+// and 24-bit color, and reads the keyboard and mouse from the TTY. It needs no window system,
+// so examples and tests can run over SSH. Build with the nogl tag to leave OpenGL and its cgo
+// dependencies out of programs that use it.
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/graphics/diag"
+	"github.com/dfirebaugh/banana/graphics/software"
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l\x1b[2J"
+	leaveScreen = "\x1b[0m\x1b[?25h\x1b[?1049l"
+	// Mouse reports are sent for every motion, in the SGR format.
+	enableMouse  = "\x1b[?1003h\x1b[?1006h"
+	disableMouse = "\x1b[?1003l\x1b[?1006l"
+)
+
+// Terminals do not report key releases. A key is released when it has not been seen for a while:
+// long enough after the first press for the terminal's key repeat to start, and shortly after a repeat.
+const (
+	firstRepeatDelay = 550 * time.Millisecond
+	repeatDelay      = 100 * time.Millisecond
+)
+
+var _ graphics.GraphicsBackend = (*Backend)(nil)
+
+type heldKey struct {
+	last    time.Time
+	repeats int
+}
+
+// Backend rasterizes with the software backend into a Display on standard output.
+type Backend struct {
+	*software.Backend
+	display *Display
+	tty     *tty
+	keys    chan keyInput
+	held    map[input.Key]*heldKey
+	title   string
+	closed  bool
+}
+
+// New creates a backend of width by height pixels. A terminal cell holds two pixels, one above the other.
+// When width or height is zero it is fit to the size of the terminal.
+// Input is read when standard input is a terminal.
+func New(width, height int) (*Backend, error) {
+	if width == 0 || height == 0 {
+		cols, rows, err := terminalSize(os.Stdout)
+		if err != nil {
+			return nil, fmt.Errorf("can not fit the terminal size: %w", err)
+		}
+		if width == 0 {
+			width = cols
+		}
+		if height == 0 {
+			height = rows * 2
+		}
+	}
+
+	b := &Backend{
+		keys: make(chan keyInput, 256),
+		held: make(map[input.Key]*heldKey),
+	}
+	t, err := openTTY(os.Stdin)
+	if err != nil {
+		diag.Warnf("Reading input is disabled: %v", err)
+	} else {
+		b.tty = t
+		go b.read()
+	}
+
+	os.Stdout.WriteString(enterScreen)
+	if b.tty != nil {
+		os.Stdout.WriteString(enableMouse)
+	}
+	b.display = NewDisplay(os.Stdout, width, height)
+	b.Backend = software.New(b.display)
+	return b, nil
+}
+
+// read sends the keys typed into the terminal to PollEvents.
+func (b *Backend) read() {
+	buf := make([]byte, 256)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		parseInput(buf[:n], func(k keyInput) {
+			b.keys <- k
+		})
+	}
+}
+
+// PollEvents delivers the input read since the last call and releases keys that are no longer repeating.
+func (b *Backend) PollEvents() bool {
+	now := time.Now()
+	for {
+		select {
+		case k := <-b.keys:
+			b.handle(k, now)
+			continue
+		default:
+		}
+		break
+	}
+
+	for key, h := range b.held {
+		delay := repeatDelay
+		if h.repeats == 0 {
+			delay = firstRepeatDelay
+		}
+		if now.Sub(h.last) > delay {
+			delete(b.held, key)
+			b.SendEvent(input.Event{Type: input.KeyRelease, Key: key})
+		}
+	}
+	return !b.closed && b.Backend.PollEvents()
+}
+
+func (b *Backend) handle(k keyInput, now time.Time) {
+	switch {
+	case k.quit:
+		b.Close()
+	case k.mouse != nil:
+		if k.mouse.Type != input.MouseMove {
+			b.SendEvent(input.Event{Type: input.MouseMove, X: k.mouse.X, Y: k.mouse.Y})
+		}
+		b.SendEvent(*k.mouse)
+	default:
+		if h, ok := b.held[k.key]; ok {
+			h.last = now
+			h.repeats++
+			return
+		}
+		b.held[k.key] = &heldKey{last: now}
+		b.SendEvent(input.Event{Type: input.KeyPress, Key: k.key})
+	}
+}
+
+// SetWindowTitle sets the title of the terminal window.
+func (b *Backend) SetWindowTitle(title string) {
+	title = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, title)
+	if title == b.title {
+		return
+	}
+	b.title = title
+	fmt.Fprintf(os.Stdout, "\x1b]0;%s\x07", title)
+}
+
+// Close restores the terminal.
+func (b *Backend) Close() {
+	if b.closed {
+		return
+	}
+	b.closed = true
+	if b.tty != nil {
+		os.Stdout.WriteString(disableMouse)
+		if err := b.tty.restore(); err != nil {
+			diag.Errorf("Failed to restore the terminal: %v", err)
+		}
+	}
+	os.Stdout.WriteString(leaveScreen)
+	b.Backend.Close()
+}
+
+func (b *Backend) DestroyWindow() {
+	b.Close()
+}
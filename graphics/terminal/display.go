@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// upperHalfBlock fills the top half of a cell with the foreground color.
+// The background color shows through the bottom half, so every cell holds two pixels.
+const upperHalfBlock = "▀"
+
+// span is the range of dirty columns in a row of cells. It is empty when max < min.
+type span struct {
+	min, max int
+}
+
+// Display is an fb.Displayer that draws into a terminal with 24-bit color escapes.
+// Only the cells that were set since the last call to Display are written.
+type Display struct {
+	img   *image.RGBA
+	out   *bufio.Writer
+	dirty []span
+}
+
+// NewDisplay creates a display of width by height pixels drawn from the top left corner of the terminal.
+// It takes height/2 rows of width cells.
+func NewDisplay(w io.Writer, width, height int) *Display {
+	d := &Display{
+		img:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		out:   bufio.NewWriterSize(w, 64*1024),
+		dirty: make([]span, (height+1)/2),
+	}
+	d.Redraw()
+	return d
+}
+
+func (d *Display) SetPixel(x, y int16, c color.RGBA) {
+	if !image.Pt(int(x), int(y)).In(d.img.Rect) {
+		return
+	}
+	d.img.SetRGBA(int(x), int(y), c)
+
+	row := &d.dirty[y/2]
+	if row.max < row.min {
+		row.min, row.max = int(x), int(x)
+		return
+	}
+	row.min = min(row.min, int(x))
+	row.max = max(row.max, int(x))
+}
+
+func (d *Display) Size() (int16, int16) {
+	return int16(d.img.Rect.Dx()), int16(d.img.Rect.Dy())
+}
+
+// Redraw marks every cell as dirty, for when the terminal was cleared or scrolled.
+func (d *Display) Redraw() {
+	for i := range d.dirty {
+		d.dirty[i] = span{min: 0, max: d.img.Rect.Dx() - 1}
+	}
+}
+
+// Display writes the dirty cells to the terminal.
+func (d *Display) Display() error {
+	for row := range d.dirty {
+		s := d.dirty[row]
+		if s.max < s.min {
+			continue
+		}
+		d.dirty[row] = span{min: 0, max: -1}
+
+		fmt.Fprintf(d.out, "\x1b[%d;%dH", row+1, s.min+1)
+		var fg, bg color.RGBA
+		for x := s.min; x <= s.max; x++ {
+			top := d.img.RGBAAt(x, row*2)
+			bottom := d.img.RGBAAt(x, row*2+1)
+			if x == s.min || top != fg {
+				fmt.Fprintf(d.out, "\x1b[38;2;%d;%d;%dm", top.R, top.G, top.B)
+				fg = top
+			}
+			if x == s.min || bottom != bg {
+				fmt.Fprintf(d.out, "\x1b[48;2;%d;%d;%dm", bottom.R, bottom.G, bottom.B)
+				bg = bottom
+			}
+			d.out.WriteString(upperHalfBlock)
+		}
+	}
+	d.out.WriteString("\x1b[0m")
+	return d.out.Flush()
+}
@@ -0,0 +1,152 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+// keyInput is a key read from the terminal. Terminals only report presses, releases are inferred.
+type keyInput struct {
+	key   input.Key
+	quit  bool
+	mouse *input.Event
+}
+
+// csiKeys maps the final byte of a CSI or SS3 sequence to its key.
+var csiKeys = map[byte]input.Key{
+	'A': input.KeyUp, 'B': input.KeyDown, 'C': input.KeyRight, 'D': input.KeyLeft,
+	'H': input.KeyHome, 'F': input.KeyEnd,
+	'P': input.KeyF1, 'Q': input.KeyF2, 'R': input.KeyF3, 'S': input.KeyF4,
+}
+
+// tildeKeys maps the parameter of a CSI sequence ending in '~' to its key.
+var tildeKeys = map[int]input.Key{
+	1: input.KeyHome, 2: input.KeyInsert, 3: input.KeyDelete, 4: input.KeyEnd,
+	5: input.KeyPageUp, 6: input.KeyPageDown, 7: input.KeyHome, 8: input.KeyEnd,
+	11: input.KeyF1, 12: input.KeyF2, 13: input.KeyF3, 14: input.KeyF4,
+	15: input.KeyF5, 17: input.KeyF6, 18: input.KeyF7, 19: input.KeyF8,
+	20: input.KeyF9, 21: input.KeyF10, 23: input.KeyF11, 24: input.KeyF12,
+}
+
+// parseInput translates bytes read from a terminal in raw mode into keys and mouse events.
+// Sequences that are not understood are dropped.
+func parseInput(data []byte, emit func(keyInput)) {
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == 0x1b:
+			i += parseEscape(data[i:], emit)
+			continue
+		case c == 0x03:
+			emit(keyInput{quit: true})
+		case c == '\r' || c == '\n':
+			emit(keyInput{key: input.KeyEnter})
+		case c == '\t':
+			emit(keyInput{key: input.KeyTab})
+		case c == 0x7f || c == 0x08:
+			emit(keyInput{key: input.KeyBackspace})
+		case c >= 0x01 && c <= 0x1a:
+			// Control combinations arrive as the letter's position in the alphabet.
+			emit(keyInput{key: input.KeyA + input.Key(c-1)})
+		case c < 0x20:
+			// Ctrl-Space and the control codes of punctuation have no key.
+		default:
+			if key, ok := input.KeyForChar(rune(c)); ok {
+				emit(keyInput{key: key})
+			}
+		}
+		i++
+	}
+}
+
+// parseEscape parses the sequence starting with the escape at data[0] and returns its length.
+func parseEscape(data []byte, emit func(keyInput)) int {
+	if len(data) == 1 {
+		emit(keyInput{key: input.KeyEscape})
+		return 1
+	}
+
+	switch data[1] {
+	case '[':
+		end := 2
+		for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+			end++
+		}
+		if end == len(data) {
+			return len(data)
+		}
+		params, final := string(data[2:end]), data[end]
+		if strings.HasPrefix(params, "<") {
+			if evt, ok := parseMouse(params[1:], final); ok {
+				emit(keyInput{mouse: &evt})
+			}
+			return end + 1
+		}
+		if final == '~' {
+			n, _ := strconv.Atoi(strings.Split(params, ";")[0])
+			if key, ok := tildeKeys[n]; ok {
+				emit(keyInput{key: key})
+			}
+		} else if key, ok := csiKeys[final]; ok {
+			emit(keyInput{key: key})
+		}
+		return end + 1
+	case 'O':
+		if len(data) > 2 {
+			if key, ok := csiKeys[data[2]]; ok {
+				emit(keyInput{key: key})
+			}
+			return 3
+		}
+		return 2
+	default:
+		if data[1] < 0x20 {
+			emit(keyInput{key: input.KeyEscape})
+			return 1
+		}
+		// Alt combinations arrive as an escape followed by the key.
+		if key, ok := input.KeyForChar(rune(data[1])); ok {
+			emit(keyInput{key: key})
+		}
+		return 2
+	}
+}
+
+// parseMouse parses the parameters of an SGR mouse report, "button;column;row".
+// Cells are one pixel wide and two pixels tall.
+func parseMouse(params string, final byte) (input.Event, bool) {
+	fields := strings.Split(params, ";")
+	if len(fields) != 3 {
+		return input.Event{}, false
+	}
+	code, err1 := strconv.Atoi(fields[0])
+	col, err2 := strconv.Atoi(fields[1])
+	row, err3 := strconv.Atoi(fields[2])
+	if err1 != nil || err2 != nil || err3 != nil || code&64 != 0 {
+		return input.Event{}, false
+	}
+
+	evt := input.Event{X: col - 1, Y: (row - 1) * 2}
+	switch {
+	case code&32 != 0:
+		evt.Type = input.MouseMove
+		return evt, true
+	case final == 'M':
+		evt.Type = input.MousePress
+	default:
+		evt.Type = input.MouseRelease
+	}
+	switch code & 3 {
+	case 0:
+		evt.MouseButton = input.MouseButtonLeft
+	case 1:
+		evt.MouseButton = input.MouseButtonMiddle
+	case 2:
+		evt.MouseButton = input.MouseButtonRight
+	default:
+		return input.Event{}, false
+	}
+	return evt, true
+}
@@ -0,0 +1,69 @@
+package terminal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+func TestParseInput(t *testing.T) {
+	press := func(x, y int, button input.MouseButton) keyInput {
+		return keyInput{mouse: &input.Event{Type: input.MousePress, X: x, Y: y, MouseButton: button}}
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want []keyInput
+	}{
+		{"letters", "aZ1", []keyInput{{key: input.KeyA}, {key: input.KeyZ}, {key: input.Key1}}},
+		{"enter and tab", "\r\n\t", []keyInput{{key: input.KeyEnter}, {key: input.KeyEnter}, {key: input.KeyTab}}},
+		{"backspace", "\x7f\x08", []keyInput{{key: input.KeyBackspace}, {key: input.KeyBackspace}}},
+		{"ctrl-c quits", "\x03", []keyInput{{quit: true}}},
+		{"ctrl letters", "\x01\x1a", []keyInput{{key: input.KeyA}, {key: input.KeyZ}}},
+		{"ctrl-space and ctrl punctuation are dropped", "\x00\x1c\x1d\x1e\x1fa", []keyInput{{key: input.KeyA}}},
+		{"lone escape", "\x1b", []keyInput{{key: input.KeyEscape}}},
+		{"arrows", "\x1b[A\x1b[D\x1bOB", []keyInput{{key: input.KeyUp}, {key: input.KeyLeft}, {key: input.KeyDown}}},
+		{"modified arrow", "\x1b[1;5C", []keyInput{{key: input.KeyRight}}},
+		{"tilde keys", "\x1b[3~\x1b[24~", []keyInput{{key: input.KeyDelete}, {key: input.KeyF12}}},
+		{"unknown tilde key", "\x1b[99~a", []keyInput{{key: input.KeyA}}},
+		{"alt combination", "\x1bx", []keyInput{{key: input.KeyX}}},
+		{"escape before a control code", "\x1b\x01", []keyInput{{key: input.KeyEscape}, {key: input.KeyA}}},
+		{"mouse press", "\x1b[<0;3;2M", []keyInput{press(2, 2, input.MouseButtonLeft)}},
+		{"truncated sequence", "\x1b[1;5", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []keyInput
+			parseInput([]byte(tt.data), func(k keyInput) { got = append(got, k) })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseInput(%q) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMouse(t *testing.T) {
+	tests := []struct {
+		params string
+		final  byte
+		want   input.Event
+		ok     bool
+	}{
+		{"0;1;1", 'M', input.Event{Type: input.MousePress, MouseButton: input.MouseButtonLeft}, true},
+		{"1;10;4", 'M', input.Event{Type: input.MousePress, MouseButton: input.MouseButtonMiddle, X: 9, Y: 6}, true},
+		{"2;5;3", 'm', input.Event{Type: input.MouseRelease, MouseButton: input.MouseButtonRight, X: 4, Y: 4}, true},
+		{"35;7;2", 'M', input.Event{Type: input.MouseMove, X: 6, Y: 2}, true},
+		{"64;1;1", 'M', input.Event{}, false},
+		{"3;1;1", 'm', input.Event{}, false},
+		{"0;1", 'M', input.Event{}, false},
+		{"a;1;1", 'M', input.Event{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseMouse(tt.params, tt.final)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseMouse(%q, %q) = %+v, %v, want %+v, %v", tt.params, tt.final, got, ok, tt.want, tt.ok)
+		}
+	}
+}
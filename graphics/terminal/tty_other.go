@@ -0,0 +1,24 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package terminal
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoTTY = errors.New("terminal input is not supported on this platform")
+
+type tty struct{}
+
+func openTTY(f *os.File) (*tty, error) {
+	return nil, errNoTTY
+}
+
+func (t *tty) restore() error {
+	return nil
+}
+
+func terminalSize(f *os.File) (int, int, error) {
+	return 0, 0, errNoTTY
+}
@@ -1,17 +1,68 @@
 package window
 
 import (
+	"fmt"
+
 	"github.com/dfirebaugh/banana/pkg/input"
-	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 type Window struct {
 	*glfw.Window
+	// Context is the version of the context that was created.
+	Context    ContextVersion
 	eventChan  chan input.Event
 	isDisposed bool
 }
 
+// ContextVersion is a version of OpenGL or, when ES is set, OpenGL ES.
+type ContextVersion struct {
+	Major, Minor int
+	ES           bool
+}
+
+func (v ContextVersion) String() string {
+	if v.ES {
+		return fmt.Sprintf("OpenGL ES %d.%d", v.Major, v.Minor)
+	}
+	return fmt.Sprintf("OpenGL %d.%d core", v.Major, v.Minor)
+}
+
+// ContextVersions are tried in order until a context can be created.
+// The renderer needs OpenGL 3.3 core or OpenGL ES 3.0. Newer versions are asked for first,
+// since some drivers hand out their best context only when it is requested by version.
+var ContextVersions = []ContextVersion{
+	{Major: 4, Minor: 6},
+	{Major: 4, Minor: 5},
+	{Major: 4, Minor: 3},
+	{Major: 4, Minor: 1},
+	{Major: 3, Minor: 3},
+	{Major: 3, Minor: 0, ES: true},
+}
+
+// DebugContext asks for a debug context, on which drivers report every error and performance warning.
+// It has to be set before the window is created.
+var DebugContext bool
+
+func setContextHints(version ContextVersion) {
+	glfw.DefaultWindowHints()
+	if DebugContext {
+		glfw.WindowHint(glfw.OpenGLDebugContext, glfw.True)
+	}
+	glfw.WindowHint(glfw.ContextVersionMajor, version.Major)
+	glfw.WindowHint(glfw.ContextVersionMinor, version.Minor)
+	if version.ES {
+		glfw.WindowHint(glfw.ClientAPI, glfw.OpenGLESAPI)
+	} else {
+		glfw.WindowHint(glfw.ClientAPI, glfw.OpenGLAPI)
+		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+		// macOS only creates core contexts that are forward compatible
+		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	}
+	glfw.WindowHint(glfw.TransparentFramebuffer, glfw.True)
+}
+
 func NewWindow(width, height int) (*Window, error) {
 	if err := glfw.Init(); err != nil {
 		return nil, err
@@ -19,21 +70,30 @@ func NewWindow(width, height int) (*Window, error) {
 
 	// glfw.WindowHint(glfw.ClientAPI, glfw.NoAPI)
 	// glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 6)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.TransparentFramebuffer, glfw.True)
 
 	w := &Window{
 		eventChan:  make(chan input.Event, 100),
 		isDisposed: false,
 	}
 
-	win, err := glfw.CreateWindow(640, 480, "sample title", nil, nil)
+	var win *glfw.Window
+	var err error
+	for _, version := range ContextVersions {
+		setContextHints(version)
+		win, err = glfw.CreateWindow(640, 480, "sample title", nil, nil)
+		if err != nil && version.ES {
+			// GLX and WGL often can not create ES contexts while EGL can
+			glfw.WindowHint(glfw.ContextCreationAPI, glfw.EGLContextAPI)
+			win, err = glfw.CreateWindow(640, 480, "sample title", nil, nil)
+		}
+		if err == nil {
+			w.Context = version
+			break
+		}
+	}
 	if err != nil {
 		glfw.Terminate()
-		w.DestroyWindow()
-		return nil, err
+		return nil, fmt.Errorf("no supported OpenGL context could be created: %w", err)
 	}
 
 	win.MakeContextCurrent()
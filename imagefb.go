@@ -0,0 +1,72 @@
+package banana
+
+import (
+	"image"
+
+	"github.com/dfirebaugh/banana/graphics"
+	"github.com/dfirebaugh/banana/pkg/fb"
+)
+
+// streamedTexture is the texture an ImageFB is drawn from.
+type streamedTexture struct {
+	id   uint32
+	size image.Point
+}
+
+// RenderImageFB draws a CPU framebuffer. It is kept in a texture of its own, and only the area
+// changed since the last call is uploaded, so it can be drawn every frame.
+// Size the area with RectWidth and RectHeight like RenderTexture; when they are zero the whole
+// framebuffer is drawn, and a zero Scale without a desired size draws it at its size.
+// A nil options draws it at the top left of the screen.
+func RenderImageFB(f *fb.ImageFB, options *TextureRenderOptions) {
+	ensureSetupCompletion()
+	if banana.imageFBTextures == nil {
+		banana.imageFBTextures = make(map[*fb.ImageFB]*streamedTexture)
+	}
+
+	img := f.ToImage()
+	size := img.Rect.Size()
+	texture, ok := banana.imageFBTextures[f]
+	switch {
+	case !ok || texture.size != size:
+		if ok {
+			banana.graphicsBackend.DeleteTexture(texture.id)
+		}
+		texture = &streamedTexture{
+			id:   banana.graphicsBackend.UploadTextureWithOptions(img, graphics.TextureOptions{Standalone: true}),
+			size: size,
+		}
+		banana.imageFBTextures[f] = texture
+	case !f.Dirty().Empty():
+		dirty := f.Dirty()
+		banana.graphicsBackend.UpdateTexture(texture.id, img.SubImage(dirty), dirty.Min.X, dirty.Min.Y)
+	}
+	f.ClearDirty()
+
+	banana.graphicsBackend.RenderTexture(texture.id, framebufferOptions(options, size))
+}
+
+// framebufferOptions fills in the size of a CPU framebuffer where options leave it out.
+func framebufferOptions(options *TextureRenderOptions, size image.Point) *graphics.TextureRenderOptions {
+	opts := TextureRenderOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.RectWidth == 0 && opts.RectHeight == 0 {
+		opts.RectWidth, opts.RectHeight = float32(size.X), float32(size.Y)
+	}
+	if opts.Scale == 0 && opts.DesiredWidth == 0 && opts.DesiredHeight == 0 {
+		opts.Scale = 1
+	}
+	return opts.toGraphicsOptions()
+}
+
+// ReleaseImageFB frees the texture RenderImageFB keeps for f.
+func ReleaseImageFB(f *fb.ImageFB) {
+	texture, ok := banana.imageFBTextures[f]
+	if !ok {
+		return
+	}
+	banana.graphicsBackend.DeleteTexture(texture.id)
+	delete(banana.imageFBTextures, f)
+}
@@ -0,0 +1,51 @@
+package banana
+
+import (
+	"image"
+
+	"github.com/dfirebaugh/banana/pkg/fb"
+)
+
+// RenderIndexedFB draws an indexed framebuffer. Its indices and palette are kept in textures of their own
+// and resolved on the GPU, so changing the palette with SetPalette or CyclePalette only uploads the palette.
+// Indices are uploaded for the area changed since the last call. Options work as for RenderImageFB.
+func RenderIndexedFB(f *fb.IndexedFB, options *TextureRenderOptions) {
+	ensureSetupCompletion()
+	if banana.indexedFBTextures == nil {
+		banana.indexedFBTextures = make(map[*fb.IndexedFB]*streamedTexture)
+	}
+
+	img := f.ToImage()
+	size := img.Rect.Size()
+	texture, ok := banana.indexedFBTextures[f]
+	if !ok || texture.size != size {
+		if ok {
+			banana.graphicsBackend.DeleteTexture(texture.id)
+		}
+		texture = &streamedTexture{
+			id:   banana.graphicsBackend.UploadIndexedTexture(img),
+			size: size,
+		}
+		banana.indexedFBTextures[f] = texture
+	} else {
+		if dirty := f.Dirty(); !dirty.Empty() {
+			banana.graphicsBackend.UpdateIndexedTexture(texture.id, img.SubImage(dirty).(*image.Paletted), dirty.Min.X, dirty.Min.Y)
+		}
+		if f.PaletteDirty() {
+			banana.graphicsBackend.UpdatePalette(texture.id, img.Palette)
+		}
+	}
+	f.ClearDirty()
+
+	banana.graphicsBackend.RenderIndexedTexture(texture.id, framebufferOptions(options, size))
+}
+
+// ReleaseIndexedFB frees the textures RenderIndexedFB keeps for f.
+func ReleaseIndexedFB(f *fb.IndexedFB) {
+	texture, ok := banana.indexedFBTextures[f]
+	if !ok {
+		return
+	}
+	banana.graphicsBackend.DeleteTexture(texture.id)
+	delete(banana.indexedFBTextures, f)
+}
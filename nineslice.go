@@ -0,0 +1,46 @@
+package banana
+
+import (
+	"image/color"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+// Insets are the distances in texture pixels from each edge of a texture
+// to the stretchable center of a nine-slice.
+type Insets struct {
+	Left, Top, Right, Bottom float32
+}
+
+type NineSliceRenderOptions struct {
+	X, Y          float32
+	Width, Height float32
+	// Scale is applied to the fixed corners and edges. The zero value is treated as 1.
+	Scale float32
+	Tint  color.Color
+	// Alpha scales the opacity of the nine-slice. A nil Alpha leaves it fully opaque.
+	Alpha *float32
+}
+
+// RenderNineSlice stretches the center and edges of a texture to fill the destination rectangle
+// while keeping its corners fixed.
+func RenderNineSlice(textureHandle uint32, insets Insets, options *NineSliceRenderOptions) {
+	ensureSetupCompletion()
+	banana.graphicsBackend.RenderNineSlice(
+		textureHandle,
+		graphics.Insets{
+			Left:   insets.Left,
+			Top:    insets.Top,
+			Right:  insets.Right,
+			Bottom: insets.Bottom,
+		},
+		&graphics.NineSliceRenderOptions{
+			X:      options.X,
+			Y:      options.Y,
+			Width:  options.Width,
+			Height: options.Height,
+			Scale:  options.Scale,
+			Tint:   options.Tint,
+			Alpha:  options.Alpha,
+		})
+}
@@ -0,0 +1,121 @@
+package fb
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// The drawing methods take coordinates in pixels from the top left corner and clip to the framebuffer.
+// Colors are blended over what is already drawn using their alpha.
+
+// Fill replaces every pixel with c.
+func (i *ImageFB) Fill(c color.Color) {
+	draw.Draw(i.img, i.img.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+	i.dirty = i.img.Rect
+}
+
+// blend draws a premultiplied color over the pixel at x, y.
+func (i *ImageFB) blend(x, y int, c color.RGBA) {
+	if !image.Pt(x, y).In(i.img.Rect) || c.A == 0 {
+		return
+	}
+	i.dirty = i.dirty.Union(image.Rect(x, y, x+1, y+1))
+	if c.A == 255 {
+		i.img.SetRGBA(x, y, c)
+		return
+	}
+	o := i.img.PixOffset(x, y)
+	p := i.img.Pix[o : o+4 : o+4]
+	inv := uint32(255 - c.A)
+	p[0] = c.R + uint8((uint32(p[0])*inv+127)/255)
+	p[1] = c.G + uint8((uint32(p[1])*inv+127)/255)
+	p[2] = c.B + uint8((uint32(p[2])*inv+127)/255)
+	p[3] = c.A + uint8((uint32(p[3])*inv+127)/255)
+}
+
+// plotter returns the functions that draw c for the rasterizers.
+func (i *ImageFB) plotter(c color.Color) (plotFunc, spanFunc) {
+	col := color.RGBAModel.Convert(c).(color.RGBA)
+	plot := func(x, y int) {
+		i.blend(x, y, col)
+	}
+	span := func(x0, x1, y int) {
+		x0, x1, ok := clipSpan(x0, x1, y, i.img.Rect)
+		if !ok {
+			return
+		}
+		for x := x0; x <= x1; x++ {
+			i.blend(x, y, col)
+		}
+	}
+	return plot, span
+}
+
+// DrawLine draws a one pixel wide line from x0, y0 to x1, y1 with Bresenham's algorithm.
+// Both end points are drawn.
+func (i *ImageFB) DrawLine(x0, y0, x1, y1 int, c color.Color) {
+	plot, _ := i.plotter(c)
+	rasterLine(x0, y0, x1, y1, plot)
+}
+
+// DrawRect draws the one pixel wide outline of a rectangle.
+func (i *ImageFB) DrawRect(x, y, width, height int, c color.Color) {
+	plot, span := i.plotter(c)
+	rasterRect(x, y, width, height, plot, span)
+}
+
+// FillRect fills a rectangle.
+func (i *ImageFB) FillRect(x, y, width, height int, c color.Color) {
+	_, span := i.plotter(c)
+	rasterFillRect(x, y, width, height, span)
+}
+
+// DrawCircle draws the one pixel wide outline of a circle with the midpoint algorithm.
+func (i *ImageFB) DrawCircle(cx, cy, radius int, c color.Color) {
+	plot, _ := i.plotter(c)
+	rasterCircle(cx, cy, radius, plot)
+}
+
+// FillCircle fills a circle. Its edge matches DrawCircle.
+func (i *ImageFB) FillCircle(cx, cy, radius int, c color.Color) {
+	_, span := i.plotter(c)
+	rasterFillCircle(cx, cy, radius, span)
+}
+
+// FillPolygon fills the polygon through points with the even-odd rule.
+// A pixel is filled when its center is inside, so polygons that share an edge do not overlap.
+func (i *ImageFB) FillPolygon(points []image.Point, c color.Color) {
+	_, span := i.plotter(c)
+	rasterPolygon(points, i.img.Rect, span)
+}
+
+// DrawText draws text with its top left corner at x, y. Every pixel of the font is drawn as a
+// scale by scale square. Glyphs are one pixel apart and '\n' starts a new line.
+// A nil font draws with Font5x7.
+func (i *ImageFB) DrawText(font *BitmapFont, text string, x, y, scale int, c color.Color) {
+	_, span := i.plotter(c)
+	rasterText(font, text, x, y, scale, span)
+}
+
+// Blit draws src over the framebuffer with its top left corner at x, y.
+func (i *ImageFB) Blit(src image.Image, x, y int) {
+	i.BlitAlpha(src, src.Bounds(), x, y, 1)
+}
+
+// BlitAlpha draws the sr region of src over the framebuffer with its top left corner at x, y.
+// The alpha of src is multiplied by alpha, between 0 and 1.
+func (i *ImageFB) BlitAlpha(src image.Image, sr image.Rectangle, x, y int, alpha float32) {
+	sr = sr.Intersect(src.Bounds())
+	dr := image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x+sr.Dx(), y+sr.Dy())}
+	if alpha <= 0 {
+		return
+	}
+	i.MarkDirty(dr)
+	if alpha >= 1 {
+		draw.Draw(i.img, dr, src, sr.Min, draw.Over)
+		return
+	}
+	mask := image.NewUniform(color.Alpha{A: uint8(alpha*255 + 0.5)})
+	draw.DrawMask(i.img, dr, src, sr.Min, mask, image.Point{}, draw.Over)
+}
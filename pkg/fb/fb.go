@@ -15,6 +15,8 @@ type Displayer interface {
 // ImageFB encapsulates an in-memory image framebuffer.
 type ImageFB struct {
 	img *image.RGBA
+	// dirty is the area changed since the last ClearDirty.
+	dirty image.Rectangle
 }
 
 // New creates and returns a new instance of ImageFB with the specified width and height.
@@ -40,6 +42,7 @@ func (i *ImageFB) SetPixel(x, y int16, c color.RGBA) {
 		return
 	}
 	i.img.SetRGBA(int(x), int(y), c)
+	i.dirty = i.dirty.Union(image.Rect(int(x), int(y), int(x)+1, int(y)+1))
 }
 
 // Display implements the Display method for the displayer interface.
@@ -59,3 +62,19 @@ func (i *ImageFB) Size() (int16, int16) {
 func (i *ImageFB) ToImage() *image.RGBA {
 	return i.img
 }
+
+// Dirty returns the area changed by drawing since the last call to ClearDirty.
+// It is empty when nothing changed.
+func (i *ImageFB) Dirty() image.Rectangle {
+	return i.dirty
+}
+
+// ClearDirty forgets the changed area, for when its pixels have been copied elsewhere.
+func (i *ImageFB) ClearDirty() {
+	i.dirty = image.Rectangle{}
+}
+
+// MarkDirty records a change made directly to the image returned by ToImage.
+func (i *ImageFB) MarkDirty(r image.Rectangle) {
+	i.dirty = i.dirty.Union(r.Intersect(i.img.Rect))
+}
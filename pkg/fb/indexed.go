@@ -0,0 +1,228 @@
+package fb
+
+import (
+	"image"
+	"image/color"
+)
+
+// PaletteSize is the number of colors of an IndexedFB palette.
+const PaletteSize = 256
+
+// IndexedFB is an 8-bit framebuffer whose pixels are indices into a 256 color palette.
+// Changing the palette recolors every pixel that uses the changed entries, which is how
+// palette cycling and swapping effects are done without touching the pixels.
+type IndexedFB struct {
+	img *image.Paletted
+	// dirty is the area changed since the last ClearDirty.
+	dirty        image.Rectangle
+	paletteDirty bool
+}
+
+// NewIndexed creates an indexed framebuffer of width by height pixels, all set to index 0.
+// Entries missing from palette are transparent.
+func NewIndexed(width, height int, palette color.Palette) *IndexedFB {
+	i := &IndexedFB{
+		img: image.NewPaletted(image.Rect(0, 0, width, height), make(color.Palette, PaletteSize)),
+	}
+	i.SetPalette(palette)
+	return i
+}
+
+func (i *IndexedFB) Width() int {
+	return i.img.Rect.Dx()
+}
+
+func (i *IndexedFB) Height() int {
+	return i.img.Rect.Dy()
+}
+
+// Size returns the width and height dimensions of the framebuffer.
+func (i *IndexedFB) Size() (int16, int16) {
+	return int16(i.img.Rect.Dx()), int16(i.img.Rect.Dy())
+}
+
+// SetPixel sets the pixel to the palette entry closest to c, so an IndexedFB can be used as a Displayer.
+// It does nothing if the coordinates are out of bounds.
+func (i *IndexedFB) SetPixel(x, y int16, c color.RGBA) {
+	i.SetIndex(int(x), int(y), uint8(i.img.Palette.Index(c)))
+}
+
+// Display implements the Display method for the displayer interface. It does nothing.
+func (i *IndexedFB) Display() error {
+	return nil
+}
+
+// SetIndex sets the pixel at x, y to a palette index.
+func (i *IndexedFB) SetIndex(x, y int, index uint8) {
+	if !image.Pt(x, y).In(i.img.Rect) {
+		return
+	}
+	i.img.SetColorIndex(x, y, index)
+	i.dirty = i.dirty.Union(image.Rect(x, y, x+1, y+1))
+}
+
+// IndexAt returns the palette index of the pixel at x, y.
+func (i *IndexedFB) IndexAt(x, y int) uint8 {
+	return i.img.ColorIndexAt(x, y)
+}
+
+// ToImage returns the internal image. Its palette always has PaletteSize entries.
+func (i *IndexedFB) ToImage() *image.Paletted {
+	return i.img
+}
+
+// Palette returns a copy of the palette.
+func (i *IndexedFB) Palette() color.Palette {
+	return append(color.Palette(nil), i.img.Palette...)
+}
+
+// SetPalette replaces the palette. Entries past the end of palette become transparent.
+func (i *IndexedFB) SetPalette(palette color.Palette) {
+	for n := range i.img.Palette {
+		if n < len(palette) {
+			i.img.Palette[n] = palette[n]
+		} else {
+			i.img.Palette[n] = color.RGBA{}
+		}
+	}
+	i.paletteDirty = true
+}
+
+// SetPaletteColor replaces one entry of the palette.
+func (i *IndexedFB) SetPaletteColor(index uint8, c color.Color) {
+	i.img.Palette[index] = c
+	i.paletteDirty = true
+}
+
+// CyclePalette rotates the entries from first to last inclusive by steps, towards higher indices
+// for positive steps. Pixels drawn with a gradient in that range appear to flow.
+func (i *IndexedFB) CyclePalette(first, last uint8, steps int) {
+	if last <= first {
+		return
+	}
+	entries := i.img.Palette[first : int(last)+1]
+	n := len(entries)
+	steps = ((steps % n) + n) % n
+	if steps == 0 {
+		return
+	}
+	rotated := append(append(color.Palette(nil), entries[n-steps:]...), entries[:n-steps]...)
+	copy(entries, rotated)
+	i.paletteDirty = true
+}
+
+// PaletteDirty reports whether the palette changed since the last ClearDirty.
+func (i *IndexedFB) PaletteDirty() bool {
+	return i.paletteDirty
+}
+
+// Dirty returns the area of pixels changed by drawing since the last call to ClearDirty.
+// It is empty when no pixel changed.
+func (i *IndexedFB) Dirty() image.Rectangle {
+	return i.dirty
+}
+
+// ClearDirty forgets the changed pixels and palette, for when they have been copied elsewhere.
+func (i *IndexedFB) ClearDirty() {
+	i.dirty = image.Rectangle{}
+	i.paletteDirty = false
+}
+
+// MarkDirty records a change made directly to the image returned by ToImage.
+func (i *IndexedFB) MarkDirty(r image.Rectangle) {
+	i.dirty = i.dirty.Union(r.Intersect(i.img.Rect))
+}
+
+// plotter returns the functions that draw index for the rasterizers.
+func (i *IndexedFB) plotter(index uint8) (plotFunc, spanFunc) {
+	plot := func(x, y int) {
+		i.SetIndex(x, y, index)
+	}
+	span := func(x0, x1, y int) {
+		x0, x1, ok := clipSpan(x0, x1, y, i.img.Rect)
+		if !ok {
+			return
+		}
+		o := i.img.PixOffset(x0, y)
+		row := i.img.Pix[o : o+x1-x0+1]
+		for n := range row {
+			row[n] = index
+		}
+		i.dirty = i.dirty.Union(image.Rect(x0, y, x1+1, y+1))
+	}
+	return plot, span
+}
+
+// The drawing methods match those of ImageFB, drawing palette indices instead of blending colors.
+
+// Fill sets every pixel to index.
+func (i *IndexedFB) Fill(index uint8) {
+	for n := range i.img.Pix {
+		i.img.Pix[n] = index
+	}
+	i.dirty = i.img.Rect
+}
+
+func (i *IndexedFB) DrawLine(x0, y0, x1, y1 int, index uint8) {
+	plot, _ := i.plotter(index)
+	rasterLine(x0, y0, x1, y1, plot)
+}
+
+func (i *IndexedFB) DrawRect(x, y, width, height int, index uint8) {
+	plot, span := i.plotter(index)
+	rasterRect(x, y, width, height, plot, span)
+}
+
+func (i *IndexedFB) FillRect(x, y, width, height int, index uint8) {
+	_, span := i.plotter(index)
+	rasterFillRect(x, y, width, height, span)
+}
+
+func (i *IndexedFB) DrawCircle(cx, cy, radius int, index uint8) {
+	plot, _ := i.plotter(index)
+	rasterCircle(cx, cy, radius, plot)
+}
+
+func (i *IndexedFB) FillCircle(cx, cy, radius int, index uint8) {
+	_, span := i.plotter(index)
+	rasterFillCircle(cx, cy, radius, span)
+}
+
+func (i *IndexedFB) FillPolygon(points []image.Point, index uint8) {
+	_, span := i.plotter(index)
+	rasterPolygon(points, i.img.Rect, span)
+}
+
+func (i *IndexedFB) DrawText(font *BitmapFont, text string, x, y, scale int, index uint8) {
+	_, span := i.plotter(index)
+	rasterText(font, text, x, y, scale, span)
+}
+
+// Blit copies the indices of src with its top left corner at x, y.
+func (i *IndexedFB) Blit(src *image.Paletted, x, y int) {
+	i.blit(src, x, y, -1)
+}
+
+// BlitTransparent copies the indices of src with its top left corner at x, y,
+// skipping the pixels set to transparent.
+func (i *IndexedFB) BlitTransparent(src *image.Paletted, x, y int, transparent uint8) {
+	i.blit(src, x, y, int(transparent))
+}
+
+func (i *IndexedFB) blit(src *image.Paletted, x, y int, transparent int) {
+	sr := src.Rect
+	dr := image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x+sr.Dx(), y+sr.Dy())}.Intersect(i.img.Rect)
+	if dr.Empty() {
+		return
+	}
+	for row := dr.Min.Y; row < dr.Max.Y; row++ {
+		for col := dr.Min.X; col < dr.Max.X; col++ {
+			index := src.ColorIndexAt(sr.Min.X+col-x, sr.Min.Y+row-y)
+			if int(index) == transparent {
+				continue
+			}
+			i.img.SetColorIndex(col, row, index)
+		}
+	}
+	i.dirty = i.dirty.Union(dr)
+}
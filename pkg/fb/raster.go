@@ -0,0 +1,184 @@
+package fb
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// The rasterizers below are shared by every framebuffer type. They call plot for single pixels
+// and span for the pixels from x0 to x1 inclusive on row y, and leave clipping to those functions.
+// Every pixel is visited once, so translucent colors are not blended twice.
+
+type plotFunc func(x, y int)
+
+type spanFunc func(x0, x1, y int)
+
+// rasterLine walks a line from x0, y0 to x1, y1 with Bresenham's algorithm. Both end points are drawn.
+func rasterLine(x0, y0, x1, y1 int, plot plotFunc) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func rasterRect(x, y, width, height int, plot plotFunc, span spanFunc) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	span(x, x+width-1, y)
+	if height > 1 {
+		span(x, x+width-1, y+height-1)
+	}
+	for row := y + 1; row < y+height-1; row++ {
+		plot(x, row)
+		if width > 1 {
+			plot(x+width-1, row)
+		}
+	}
+}
+
+func rasterFillRect(x, y, width, height int, span spanFunc) {
+	if width <= 0 {
+		return
+	}
+	for row := y; row < y+height; row++ {
+		span(x, x+width-1, row)
+	}
+}
+
+// rasterCircle walks the outline of a circle with the midpoint algorithm.
+func rasterCircle(cx, cy, radius int, plot plotFunc) {
+	if radius < 0 {
+		return
+	}
+	// quadrants mirrors a point into each quadrant, once per distinct pixel.
+	quadrants := func(x, y int) {
+		plot(cx+x, cy+y)
+		if x != 0 {
+			plot(cx-x, cy+y)
+		}
+		if y != 0 {
+			plot(cx+x, cy-y)
+		}
+		if x != 0 && y != 0 {
+			plot(cx-x, cy-y)
+		}
+	}
+	midpointCircle(radius, func(x, y int) {
+		quadrants(x, y)
+		if x != y {
+			quadrants(y, x)
+		}
+	})
+}
+
+// rasterFillCircle fills a circle whose edge matches rasterCircle.
+func rasterFillCircle(cx, cy, radius int, span spanFunc) {
+	if radius < 0 {
+		return
+	}
+	half := make([]int, radius+1)
+	midpointCircle(radius, func(x, y int) {
+		half[y] = max(half[y], x)
+		half[x] = max(half[x], y)
+	})
+	for dy, dx := range half {
+		span(cx-dx, cx+dx, cy+dy)
+		if dy != 0 {
+			span(cx-dx, cx+dx, cy-dy)
+		}
+	}
+}
+
+// midpointCircle calls fn for the points of the first octant of a circle, from x = radius, y = 0 until x = y.
+func midpointCircle(radius int, fn func(x, y int)) {
+	x, y := radius, 0
+	err := 1 - radius
+	for x >= y {
+		fn(x, y)
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+}
+
+// rasterPolygon fills the polygon through points with the even-odd rule, on the rows within clip.
+// A pixel is filled when its center is inside, so polygons that share an edge do not overlap.
+func rasterPolygon(points []image.Point, clip image.Rectangle, span spanFunc) {
+	if len(points) < 3 {
+		return
+	}
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points {
+		minY = min(minY, p.Y)
+		maxY = max(maxY, p.Y)
+	}
+	minY = max(minY, clip.Min.Y)
+	maxY = min(maxY, clip.Max.Y)
+
+	var xs []float64
+	for y := minY; y < maxY; y++ {
+		sy := float64(y) + 0.5
+		xs = xs[:0]
+		for n := range points {
+			a, b := points[n], points[(n+1)%len(points)]
+			ay, by := float64(a.Y), float64(b.Y)
+			if (ay <= sy) == (by <= sy) {
+				continue
+			}
+			t := (sy - ay) / (by - ay)
+			xs = append(xs, float64(a.X)+t*float64(b.X-a.X))
+		}
+		sort.Float64s(xs)
+		for n := 0; n+1 < len(xs); n += 2 {
+			x0 := int(math.Ceil(xs[n] - 0.5))
+			x1 := int(math.Ceil(xs[n+1]-0.5)) - 1
+			if x0 <= x1 {
+				span(x0, x1, y)
+			}
+		}
+	}
+}
+
+// clipSpan limits a span to the columns of bounds, reporting false when nothing is left.
+func clipSpan(x0, x1, y int, bounds image.Rectangle) (int, int, bool) {
+	if y < bounds.Min.Y || y >= bounds.Max.Y {
+		return 0, 0, false
+	}
+	x0 = max(x0, bounds.Min.X)
+	x1 = min(x1, bounds.Max.X-1)
+	return x0, x1, x0 <= x1
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package fb
+
+// BitmapFont is a fixed width font of one bit per pixel glyphs up to 8 pixels tall.
+// Each glyph is GlyphWidth columns; bit n of a column is row n from the top.
+type BitmapFont struct {
+	GlyphWidth, GlyphHeight int
+	// First is the rune of the first glyph. Runes outside of the font are drawn as '?'.
+	First   rune
+	Columns []byte
+}
+
+// glyph returns the columns of the glyph for r.
+func (f *BitmapFont) glyph(r rune) []byte {
+	n := int(r - f.First)
+	if r < f.First || (n+1)*f.GlyphWidth > len(f.Columns) {
+		n = int('?' - f.First)
+	}
+	return f.Columns[n*f.GlyphWidth : (n+1)*f.GlyphWidth]
+}
+
+// MeasureText returns the size in pixels of text drawn with DrawText.
+func (f *BitmapFont) MeasureText(text string, scale int) (int, int) {
+	scale = max(scale, 1)
+	var width, lineWidth, lines int
+	lines = 1
+	for _, r := range text {
+		if r == '\n' {
+			lines++
+			lineWidth = 0
+			continue
+		}
+		lineWidth++
+		width = max(width, lineWidth)
+	}
+	if width == 0 {
+		return 0, 0
+	}
+	return (width*(f.GlyphWidth+1) - 1) * scale, (lines*(f.GlyphHeight+1) - 1) * scale
+}
+
+// rasterText calls span for the rows of every set pixel of the glyphs of text.
+func rasterText(font *BitmapFont, text string, x, y, scale int, span spanFunc) {
+	if font == nil {
+		font = Font5x7
+	}
+	scale = max(scale, 1)
+	cursorX, cursorY := x, y
+	for _, r := range text {
+		if r == '\n' {
+			cursorX = x
+			cursorY += (font.GlyphHeight + 1) * scale
+			continue
+		}
+		for gx, column := range font.glyph(r) {
+			for gy := 0; gy < font.GlyphHeight; gy++ {
+				if column&(1<<gy) == 0 {
+					continue
+				}
+				px, py := cursorX+gx*scale, cursorY+gy*scale
+				for row := py; row < py+scale; row++ {
+					span(px, px+scale-1, row)
+				}
+			}
+		}
+		cursorX += (font.GlyphWidth + 1) * scale
+	}
+}
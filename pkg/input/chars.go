@@ -0,0 +1,27 @@
+package input
+
+// shiftedChars maps the characters typed with shift on a US layout to their key.
+var shiftedChars = map[rune]Key{
+	'!': Key1, '@': Key2, '#': Key3, '$': Key4, '%': Key5,
+	'^': Key6, '&': Key7, '*': Key8, '(': Key9, ')': Key0,
+	'_': KeyMinus, '+': KeyEqual, '{': KeyLeftBracket, '}': KeyRightBracket,
+	'|': KeyBackslash, ':': KeySemicolon, '"': KeyApostrophe,
+	'<': KeyComma, '>': KeyPeriod, '?': KeySlash, '~': KeyGraveAccent,
+}
+
+// KeyForChar returns the key that types a printable ASCII character on a US layout,
+// for input sources that report characters instead of keys.
+func KeyForChar(c rune) (Key, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return KeyA + Key(c-'a'), true
+	case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return Key(c), true
+	case c == ' ', c == '\'', c == ',', c == '-', c == '.', c == '/', c == ';', c == '=',
+		c == '[', c == '\\', c == ']', c == '`':
+		// printable keys share their code with the character they type
+		return Key(c), true
+	}
+	key, ok := shiftedChars[c]
+	return key, ok
+}
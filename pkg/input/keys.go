@@ -1,19 +1,21 @@
 package input
 
-import "github.com/go-gl/glfw/v3.3/glfw"
+// The values of keys, buttons and axes are the ones GLFW uses, so the OpenGL backend
+// converts GLFW's codes directly. The package does not import GLFW itself,
+// which keeps backends that run without a window free of cgo and X11.
 
 // GamepadAxis corresponds to a gamepad axis.
 type GamepadAxis int
 
 // Gamepad axis IDs.
 const (
-	AxisLeftX        = GamepadAxis(glfw.AxisLeftX)
-	AxisLeftY        = GamepadAxis(glfw.AxisLeftY)
-	AxisRightX       = GamepadAxis(glfw.AxisRightX)
-	AxisRightY       = GamepadAxis(glfw.AxisRightY)
-	AxisLeftTrigger  = GamepadAxis(glfw.AxisLeftTrigger)
-	AxisRightTrigger = GamepadAxis(glfw.AxisRightTrigger)
-	AxisLast         = GamepadAxis(glfw.AxisLast)
+	AxisLeftX        = GamepadAxis(0)
+	AxisLeftY        = GamepadAxis(1)
+	AxisRightX       = GamepadAxis(2)
+	AxisRightY       = GamepadAxis(3)
+	AxisLeftTrigger  = GamepadAxis(4)
+	AxisRightTrigger = GamepadAxis(5)
+	AxisLast         = GamepadAxis(5)
 )
 
 // GamepadButton corresponds to a gamepad button.
@@ -21,26 +23,26 @@ type GamepadButton int
 
 // Gamepad button IDs.
 const (
-	ButtonA           = GamepadButton(glfw.ButtonA)
-	ButtonB           = GamepadButton(glfw.ButtonB)
-	ButtonX           = GamepadButton(glfw.ButtonX)
-	ButtonY           = GamepadButton(glfw.ButtonY)
-	ButtonLeftBumper  = GamepadButton(glfw.ButtonLeftBumper)
-	ButtonRightBumper = GamepadButton(glfw.ButtonRightBumper)
-	ButtonBack        = GamepadButton(glfw.ButtonBack)
-	ButtonStart       = GamepadButton(glfw.ButtonStart)
-	ButtonGuide       = GamepadButton(glfw.ButtonGuide)
-	ButtonLeftThumb   = GamepadButton(glfw.ButtonLeftThumb)
-	ButtonRightThumb  = GamepadButton(glfw.ButtonRightThumb)
-	ButtonDpadUp      = GamepadButton(glfw.ButtonDpadUp)
-	ButtonDpadRight   = GamepadButton(glfw.ButtonDpadRight)
-	ButtonDpadDown    = GamepadButton(glfw.ButtonDpadDown)
-	ButtonDpadLeft    = GamepadButton(glfw.ButtonDpadLeft)
-	ButtonLast        = GamepadButton(glfw.ButtonLast)
-	ButtonCross       = GamepadButton(glfw.ButtonCross)
-	ButtonCircle      = GamepadButton(glfw.ButtonCircle)
-	ButtonSquare      = GamepadButton(glfw.ButtonSquare)
-	ButtonTriangle    = GamepadButton(glfw.ButtonTriangle)
+	ButtonA           = GamepadButton(0)
+	ButtonB           = GamepadButton(1)
+	ButtonX           = GamepadButton(2)
+	ButtonY           = GamepadButton(3)
+	ButtonLeftBumper  = GamepadButton(4)
+	ButtonRightBumper = GamepadButton(5)
+	ButtonBack        = GamepadButton(6)
+	ButtonStart       = GamepadButton(7)
+	ButtonGuide       = GamepadButton(8)
+	ButtonLeftThumb   = GamepadButton(9)
+	ButtonRightThumb  = GamepadButton(10)
+	ButtonDpadUp      = GamepadButton(11)
+	ButtonDpadRight   = GamepadButton(12)
+	ButtonDpadDown    = GamepadButton(13)
+	ButtonDpadLeft    = GamepadButton(14)
+	ButtonLast        = GamepadButton(14)
+	ButtonCross       = GamepadButton(0)
+	ButtonCircle      = GamepadButton(1)
+	ButtonSquare      = GamepadButton(2)
+	ButtonTriangle    = GamepadButton(3)
 )
 
 // // GamepadState describes the input state of a gamepad.
@@ -56,128 +58,128 @@ type Key int
 // but re-arranged to map to 7-bit ASCII for printable keys (function keys are
 // put in the 256+ range).
 const (
-	KeyUnknown      = Key(glfw.KeyUnknown)
-	KeySpace        = Key(glfw.KeySpace)
-	KeyApostrophe   = Key(glfw.KeyApostrophe)
-	KeyComma        = Key(glfw.KeyComma)
-	KeyMinus        = Key(glfw.KeyMinus)
-	KeyPeriod       = Key(glfw.KeyPeriod)
-	KeySlash        = Key(glfw.KeySlash)
-	Key0            = Key(glfw.Key0)
-	Key1            = Key(glfw.Key1)
-	Key2            = Key(glfw.Key2)
-	Key3            = Key(glfw.Key3)
-	Key4            = Key(glfw.Key4)
-	Key5            = Key(glfw.Key5)
-	Key6            = Key(glfw.Key6)
-	Key7            = Key(glfw.Key7)
-	Key8            = Key(glfw.Key8)
-	Key9            = Key(glfw.Key9)
-	KeySemicolon    = Key(glfw.KeySemicolon)
-	KeyEqual        = Key(glfw.KeyEqual)
-	KeyA            = Key(glfw.KeyA)
-	KeyB            = Key(glfw.KeyB)
-	KeyC            = Key(glfw.KeyC)
-	KeyD            = Key(glfw.KeyD)
-	KeyE            = Key(glfw.KeyE)
-	KeyF            = Key(glfw.KeyF)
-	KeyG            = Key(glfw.KeyG)
-	KeyH            = Key(glfw.KeyH)
-	KeyI            = Key(glfw.KeyI)
-	KeyJ            = Key(glfw.KeyJ)
-	KeyK            = Key(glfw.KeyK)
-	KeyL            = Key(glfw.KeyL)
-	KeyM            = Key(glfw.KeyM)
-	KeyN            = Key(glfw.KeyN)
-	KeyO            = Key(glfw.KeyO)
-	KeyP            = Key(glfw.KeyP)
-	KeyQ            = Key(glfw.KeyQ)
-	KeyR            = Key(glfw.KeyR)
-	KeyS            = Key(glfw.KeyS)
-	KeyT            = Key(glfw.KeyT)
-	KeyU            = Key(glfw.KeyU)
-	KeyV            = Key(glfw.KeyV)
-	KeyW            = Key(glfw.KeyW)
-	KeyX            = Key(glfw.KeyX)
-	KeyY            = Key(glfw.KeyY)
-	KeyZ            = Key(glfw.KeyZ)
-	KeyLeftBracket  = Key(glfw.KeyLeftBracket)
-	KeyBackslash    = Key(glfw.KeyBackslash)
-	KeyRightBracket = Key(glfw.KeyRightBracket)
-	KeyGraveAccent  = Key(glfw.KeyGraveAccent)
-	KeyWorld1       = Key(glfw.KeyWorld1)
-	KeyWorld2       = Key(glfw.KeyWorld2)
-	KeyEscape       = Key(glfw.KeyEscape)
-	KeyEnter        = Key(glfw.KeyEnter)
-	KeyTab          = Key(glfw.KeyTab)
-	KeyBackspace    = Key(glfw.KeyBackspace)
-	KeyInsert       = Key(glfw.KeyInsert)
-	KeyDelete       = Key(glfw.KeyDelete)
-	KeyRight        = Key(glfw.KeyRight)
-	KeyLeft         = Key(glfw.KeyLeft)
-	KeyDown         = Key(glfw.KeyDown)
-	KeyUp           = Key(glfw.KeyUp)
-	KeyPageUp       = Key(glfw.KeyPageUp)
-	KeyPageDown     = Key(glfw.KeyPageDown)
-	KeyHome         = Key(glfw.KeyHome)
-	KeyEnd          = Key(glfw.KeyEnd)
-	KeyCapsLock     = Key(glfw.KeyCapsLock)
-	KeyScrollLock   = Key(glfw.KeyScrollLock)
-	KeyNumLock      = Key(glfw.KeyNumLock)
-	KeyPrintScreen  = Key(glfw.KeyPrintScreen)
-	KeyPause        = Key(glfw.KeyPause)
-	KeyF1           = Key(glfw.KeyF1)
-	KeyF2           = Key(glfw.KeyF2)
-	KeyF3           = Key(glfw.KeyF3)
-	KeyF4           = Key(glfw.KeyF4)
-	KeyF5           = Key(glfw.KeyF5)
-	KeyF6           = Key(glfw.KeyF6)
-	KeyF7           = Key(glfw.KeyF7)
-	KeyF8           = Key(glfw.KeyF8)
-	KeyF9           = Key(glfw.KeyF9)
-	KeyF10          = Key(glfw.KeyF10)
-	KeyF11          = Key(glfw.KeyF11)
-	KeyF12          = Key(glfw.KeyF12)
-	KeyF13          = Key(glfw.KeyF13)
-	KeyF14          = Key(glfw.KeyF14)
-	KeyF15          = Key(glfw.KeyF15)
-	KeyF16          = Key(glfw.KeyF16)
-	KeyF17          = Key(glfw.KeyF17)
-	KeyF18          = Key(glfw.KeyF18)
-	KeyF19          = Key(glfw.KeyF19)
-	KeyF20          = Key(glfw.KeyF20)
-	KeyF21          = Key(glfw.KeyF21)
-	KeyF22          = Key(glfw.KeyF22)
-	KeyF23          = Key(glfw.KeyF23)
-	KeyF24          = Key(glfw.KeyF24)
-	KeyF25          = Key(glfw.KeyF25)
-	KeyKP0          = Key(glfw.KeyKP0)
-	KeyKP1          = Key(glfw.KeyKP1)
-	KeyKP2          = Key(glfw.KeyKP2)
-	KeyKP3          = Key(glfw.KeyKP3)
-	KeyKP4          = Key(glfw.KeyKP4)
-	KeyKP5          = Key(glfw.KeyKP5)
-	KeyKP6          = Key(glfw.KeyKP6)
-	KeyKP7          = Key(glfw.KeyKP7)
-	KeyKP8          = Key(glfw.KeyKP8)
-	KeyKP9          = Key(glfw.KeyKP9)
-	KeyKPDecimal    = Key(glfw.KeyKPDecimal)
-	KeyKPDivide     = Key(glfw.KeyKPDivide)
-	KeyKPMultiply   = Key(glfw.KeyKPMultiply)
-	KeyKPSubtract   = Key(glfw.KeyKPSubtract)
-	KeyKPAdd        = Key(glfw.KeyKPAdd)
-	KeyKPEnter      = Key(glfw.KeyKPEnter)
-	KeyKPEqual      = Key(glfw.KeyKPEqual)
-	KeyLeftShift    = Key(glfw.KeyLeftShift)
-	KeyLeftControl  = Key(glfw.KeyLeftControl)
-	KeyLeftAlt      = Key(glfw.KeyLeftAlt)
-	KeyLeftSuper    = Key(glfw.KeyLeftSuper)
-	KeyRightShift   = Key(glfw.KeyRightShift)
-	KeyRightControl = Key(glfw.KeyRightControl)
-	KeyRightAlt     = Key(glfw.KeyRightAlt)
-	KeyRightSuper   = Key(glfw.KeyRightSuper)
-	KeyMenu         = Key(glfw.KeyMenu)
-	KeyLast         = Key(glfw.KeyLast)
+	KeyUnknown      = Key(-1)
+	KeySpace        = Key(32)
+	KeyApostrophe   = Key(39)
+	KeyComma        = Key(44)
+	KeyMinus        = Key(45)
+	KeyPeriod       = Key(46)
+	KeySlash        = Key(47)
+	Key0            = Key(48)
+	Key1            = Key(49)
+	Key2            = Key(50)
+	Key3            = Key(51)
+	Key4            = Key(52)
+	Key5            = Key(53)
+	Key6            = Key(54)
+	Key7            = Key(55)
+	Key8            = Key(56)
+	Key9            = Key(57)
+	KeySemicolon    = Key(59)
+	KeyEqual        = Key(61)
+	KeyA            = Key(65)
+	KeyB            = Key(66)
+	KeyC            = Key(67)
+	KeyD            = Key(68)
+	KeyE            = Key(69)
+	KeyF            = Key(70)
+	KeyG            = Key(71)
+	KeyH            = Key(72)
+	KeyI            = Key(73)
+	KeyJ            = Key(74)
+	KeyK            = Key(75)
+	KeyL            = Key(76)
+	KeyM            = Key(77)
+	KeyN            = Key(78)
+	KeyO            = Key(79)
+	KeyP            = Key(80)
+	KeyQ            = Key(81)
+	KeyR            = Key(82)
+	KeyS            = Key(83)
+	KeyT            = Key(84)
+	KeyU            = Key(85)
+	KeyV            = Key(86)
+	KeyW            = Key(87)
+	KeyX            = Key(88)
+	KeyY            = Key(89)
+	KeyZ            = Key(90)
+	KeyLeftBracket  = Key(91)
+	KeyBackslash    = Key(92)
+	KeyRightBracket = Key(93)
+	KeyGraveAccent  = Key(96)
+	KeyWorld1       = Key(161)
+	KeyWorld2       = Key(162)
+	KeyEscape       = Key(256)
+	KeyEnter        = Key(257)
+	KeyTab          = Key(258)
+	KeyBackspace    = Key(259)
+	KeyInsert       = Key(260)
+	KeyDelete       = Key(261)
+	KeyRight        = Key(262)
+	KeyLeft         = Key(263)
+	KeyDown         = Key(264)
+	KeyUp           = Key(265)
+	KeyPageUp       = Key(266)
+	KeyPageDown     = Key(267)
+	KeyHome         = Key(268)
+	KeyEnd          = Key(269)
+	KeyCapsLock     = Key(280)
+	KeyScrollLock   = Key(281)
+	KeyNumLock      = Key(282)
+	KeyPrintScreen  = Key(283)
+	KeyPause        = Key(284)
+	KeyF1           = Key(290)
+	KeyF2           = Key(291)
+	KeyF3           = Key(292)
+	KeyF4           = Key(293)
+	KeyF5           = Key(294)
+	KeyF6           = Key(295)
+	KeyF7           = Key(296)
+	KeyF8           = Key(297)
+	KeyF9           = Key(298)
+	KeyF10          = Key(299)
+	KeyF11          = Key(300)
+	KeyF12          = Key(301)
+	KeyF13          = Key(302)
+	KeyF14          = Key(303)
+	KeyF15          = Key(304)
+	KeyF16          = Key(305)
+	KeyF17          = Key(306)
+	KeyF18          = Key(307)
+	KeyF19          = Key(308)
+	KeyF20          = Key(309)
+	KeyF21          = Key(310)
+	KeyF22          = Key(311)
+	KeyF23          = Key(312)
+	KeyF24          = Key(313)
+	KeyF25          = Key(314)
+	KeyKP0          = Key(320)
+	KeyKP1          = Key(321)
+	KeyKP2          = Key(322)
+	KeyKP3          = Key(323)
+	KeyKP4          = Key(324)
+	KeyKP5          = Key(325)
+	KeyKP6          = Key(326)
+	KeyKP7          = Key(327)
+	KeyKP8          = Key(328)
+	KeyKP9          = Key(329)
+	KeyKPDecimal    = Key(330)
+	KeyKPDivide     = Key(331)
+	KeyKPMultiply   = Key(332)
+	KeyKPSubtract   = Key(333)
+	KeyKPAdd        = Key(334)
+	KeyKPEnter      = Key(335)
+	KeyKPEqual      = Key(336)
+	KeyLeftShift    = Key(340)
+	KeyLeftControl  = Key(341)
+	KeyLeftAlt      = Key(342)
+	KeyLeftSuper    = Key(343)
+	KeyRightShift   = Key(344)
+	KeyRightControl = Key(345)
+	KeyRightAlt     = Key(346)
+	KeyRightSuper   = Key(347)
+	KeyMenu         = Key(348)
+	KeyLast         = Key(348)
 )
 
 // ModifierKey corresponds to a modifier key.
@@ -185,12 +187,12 @@ type ModifierKey int
 
 // Modifier keys.
 const (
-	ModShift    = ModifierKey(glfw.ModShift)
-	ModControl  = ModifierKey(glfw.ModControl)
-	ModAlt      = ModifierKey(glfw.ModAlt)
-	ModSuper    = ModifierKey(glfw.ModSuper)
-	ModCapsLock = ModifierKey(glfw.ModCapsLock)
-	ModNumLock  = ModifierKey(glfw.ModNumLock)
+	ModShift    = ModifierKey(1)
+	ModControl  = ModifierKey(2)
+	ModAlt      = ModifierKey(4)
+	ModSuper    = ModifierKey(8)
+	ModCapsLock = ModifierKey(16)
+	ModNumLock  = ModifierKey(32)
 )
 
 // MouseButton corresponds to a mouse button.
@@ -198,16 +200,16 @@ type MouseButton int
 
 // Mouse buttons.
 const (
-	MouseButton1      = MouseButton(glfw.MouseButton1)
-	MouseButton2      = MouseButton(glfw.MouseButton2)
-	MouseButton3      = MouseButton(glfw.MouseButton3)
-	MouseButton4      = MouseButton(glfw.MouseButton4)
-	MouseButton5      = MouseButton(glfw.MouseButton5)
-	MouseButton6      = MouseButton(glfw.MouseButton6)
-	MouseButton7      = MouseButton(glfw.MouseButton7)
-	MouseButton8      = MouseButton(glfw.MouseButton8)
-	MouseButtonLast   = MouseButton(glfw.MouseButtonLast)
-	MouseButtonLeft   = MouseButton(glfw.MouseButtonLeft)
-	MouseButtonRight  = MouseButton(glfw.MouseButtonRight)
-	MouseButtonMiddle = MouseButton(glfw.MouseButtonMiddle)
+	MouseButton1      = MouseButton(0)
+	MouseButton2      = MouseButton(1)
+	MouseButton3      = MouseButton(2)
+	MouseButton4      = MouseButton(3)
+	MouseButton5      = MouseButton(4)
+	MouseButton6      = MouseButton(5)
+	MouseButton7      = MouseButton(6)
+	MouseButton8      = MouseButton(7)
+	MouseButtonLast   = MouseButton(7)
+	MouseButtonLeft   = MouseButton(0)
+	MouseButtonRight  = MouseButton(1)
+	MouseButtonMiddle = MouseButton(2)
 )
@@ -9,6 +9,9 @@ import (
 type Rect struct {
 	X, Y, Width, Height, Radius float32
 	Color                       color.Color
+	// StrokeWidth draws an outline of StrokeColor along the inside of the edge.
+	StrokeWidth float32
+	StrokeColor color.Color
 }
 
 func (r *Rect) GetVertices(screenWidth, screenHeight int) []graphics.Vertex {
@@ -39,15 +42,17 @@ func (r *Rect) GetVertices(screenWidth, screenHeight int) []graphics.Vertex {
 			r.Radius = 1
 		}
 		v := graphics.Vertex{
-			FsQuadPos:  [2]float32{vertices[i*2], vertices[i*2+1]},
-			ShapePos:   [2]float32{normX + halfWidth/float32(screenWidth)*2.0, normY - halfHeight/float32(screenHeight)*2.0},
-			LocalPos:   [2]float32{vertices[i*2], vertices[i*2+1]},
-			OpCode:     graphics.OP_CODE_RECT,
-			Radius:     r.Radius,
-			Width:      r.Width,
-			Height:     r.Height,
-			Color:      color,
-			Resolution: [2]float32{float32(screenWidth), float32(screenHeight)},
+			FsQuadPos:   [2]float32{vertices[i*2], vertices[i*2+1]},
+			ShapePos:    [2]float32{normX + halfWidth/float32(screenWidth)*2.0, normY - halfHeight/float32(screenHeight)*2.0},
+			LocalPos:    [2]float32{vertices[i*2], vertices[i*2+1]},
+			OpCode:      graphics.OP_CODE_RECT,
+			Radius:      r.Radius,
+			Width:       r.Width,
+			Height:      r.Height,
+			Color:       color,
+			Resolution:  [2]float32{float32(screenWidth), float32(screenHeight)},
+			StrokeWidth: r.StrokeWidth,
+			StrokeColor: colorToVec4(r.StrokeColor),
 		}
 		result = append(result, v)
 	}
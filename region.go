@@ -0,0 +1,111 @@
+package banana
+
+import (
+	"image"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+// Region is a rectangle of a texture, such as one frame of a sprite sheet or one image packed in an atlas.
+// Its texture coordinates are worked out when it is created so drawing it does no further math.
+// Regions are small values and can be copied freely.
+type Region struct {
+	texture               uint32
+	rectX, rectY          float32
+	rectWidth, rectHeight float32
+
+	// a trimmed region is part of a larger source frame whose transparent border was cut away
+	offsetX, offsetY          float32
+	sourceWidth, sourceHeight float32
+}
+
+// SubTexture is another name for Region.
+type SubTexture = Region
+
+// NewRegion returns the part of a texture at x, y of the given size in texture pixels.
+func NewRegion(texture uint32, x, y, width, height int) Region {
+	return Region{
+		texture:    texture,
+		rectX:      float32(x),
+		rectY:      float32(y),
+		rectWidth:  float32(width),
+		rectHeight: float32(height),
+
+		sourceWidth:  float32(width),
+		sourceHeight: float32(height),
+	}
+}
+
+// NewRegionFromRect is NewRegion taking an image.Rectangle.
+func NewRegionFromRect(texture uint32, rect image.Rectangle) Region {
+	return NewRegion(texture, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+}
+
+// Texture returns the handle of the texture the region belongs to.
+func (r Region) Texture() uint32 {
+	return r.texture
+}
+
+// Rect returns the area of the texture the region covers.
+func (r Region) Rect() image.Rectangle {
+	x, y := int(r.rectX), int(r.rectY)
+	return image.Rect(x, y, x+int(r.rectWidth), y+int(r.rectHeight))
+}
+
+func (r Region) Width() int {
+	return int(r.rectWidth)
+}
+
+func (r Region) Height() int {
+	return int(r.rectHeight)
+}
+
+// Trimmed returns a copy of r that stands for a frame of the source size with r placed at offsetX, offsetY.
+// Sprite packers trim the transparent border of frames; sprites drawn from trimmed regions
+// keep their pivot where it was in the untrimmed frame so animations do not jitter.
+func (r Region) Trimmed(offsetX, offsetY, sourceWidth, sourceHeight int) Region {
+	r.offsetX, r.offsetY = float32(offsetX), float32(offsetY)
+	r.sourceWidth, r.sourceHeight = float32(sourceWidth), float32(sourceHeight)
+	return r
+}
+
+// SourceSize returns the size of the frame before it was trimmed, which is the size of the region when untrimmed.
+func (r Region) SourceSize() (int, int) {
+	return int(r.sourceWidth), int(r.sourceHeight)
+}
+
+// SubRegion returns a region relative to the top-left corner of r.
+func (r Region) SubRegion(x, y, width, height int) Region {
+	return NewRegion(r.texture, int(r.rectX)+x, int(r.rectY)+y, width, height)
+}
+
+// Split cuts the region into frames of the given size, left to right and then top to bottom.
+// Frames that do not fit completely are left out.
+func (r Region) Split(frameWidth, frameHeight int) []Region {
+	if frameWidth <= 0 || frameHeight <= 0 {
+		return nil
+	}
+	columns, rows := r.Width()/frameWidth, r.Height()/frameHeight
+	regions := make([]Region, 0, columns*rows)
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			regions = append(regions, r.SubRegion(column*frameWidth, row*frameHeight, frameWidth, frameHeight))
+		}
+	}
+	return regions
+}
+
+// Render draws the region. The Rect fields of options are replaced by the region.
+func (r Region) Render(options *TextureRenderOptions) {
+	ensureSetupCompletion()
+	graphicsOptions := options.toGraphicsOptions()
+	r.apply(graphicsOptions)
+	banana.graphicsBackend.RenderTexture(r.texture, graphicsOptions)
+}
+
+func (r Region) apply(options *graphics.TextureRenderOptions) {
+	options.RectX = r.rectX
+	options.RectY = r.rectY
+	options.RectWidth = r.rectWidth
+	options.RectHeight = r.rectHeight
+}
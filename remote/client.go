@@ -0,0 +1,416 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+// Client to server message types.
+const (
+	msgSetPixelFormat           = 0
+	msgSetEncodings             = 2
+	msgFramebufferUpdateRequest = 3
+	msgKeyEvent                 = 4
+	msgPointerEvent             = 5
+	msgClientCutText            = 6
+)
+
+const (
+	encodingRaw = 0
+	// encodingDesktopSize is the pseudo encoding that tells a client the frame changed size.
+	encodingDesktopSize = -223
+
+	securityNone = 1
+	// maxCutText bounds the clipboard text a client may send, which is read and discarded.
+	maxCutText = 1 << 20
+)
+
+// pointer buttons in the order of the bits of a pointer event's button mask
+var pointerButtons = []input.MouseButton{input.MouseButtonLeft, input.MouseButtonMiddle, input.MouseButtonRight}
+
+type updateRequest struct {
+	incremental bool
+	rect        image.Rectangle
+}
+
+type client struct {
+	server *Server
+	conn   net.Conn
+	r      *bufio.Reader
+
+	// mu guards the fields the reader sets and the writer uses.
+	mu          sync.Mutex
+	format      pixelFormat
+	desktopSize bool
+	pending     *updateRequest
+	wake        chan struct{}
+	done        chan struct{}
+
+	buttons  uint8
+	x, y     int
+	keysDown map[input.Key]bool
+}
+
+func newClient(s *Server, conn net.Conn) *client {
+	return &client{
+		server:   s,
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		format:   serverFormat,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		keysDown: make(map[input.Key]bool),
+	}
+}
+
+// serve runs the connection until the client leaves. Updates are written by a second goroutine
+// so that input keeps flowing while the client waits for a frame.
+func (c *client) serve() error {
+	defer c.conn.Close()
+	frame, seq, err := c.handshake()
+	if err != nil {
+		return err
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writeErr <- c.writeUpdates(frame, seq)
+		c.conn.Close()
+	}()
+	err = c.readMessages()
+	close(c.done)
+	c.releaseInput()
+	if werr := <-writeErr; err == nil || errors.Is(err, net.ErrClosed) {
+		err = werr
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// handshake negotiates the protocol version and security and sends the size of the first frame.
+func (c *client) handshake() (*image.RGBA, uint64, error) {
+	if _, err := io.WriteString(c.conn, "RFB 003.008\n"); err != nil {
+		return nil, 0, err
+	}
+	var version [12]byte
+	if _, err := io.ReadFull(c.r, version[:]); err != nil {
+		return nil, 0, err
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(string(version[:]), "RFB %03d.%03d\n", &major, &minor); err != nil || major != 3 {
+		return nil, 0, fmt.Errorf("unsupported protocol version %q", version)
+	}
+
+	if minor >= 7 {
+		if _, err := c.conn.Write([]byte{1, securityNone}); err != nil {
+			return nil, 0, err
+		}
+		choice, err := c.r.ReadByte()
+		if err != nil {
+			return nil, 0, err
+		}
+		if choice != securityNone {
+			return nil, 0, fmt.Errorf("client chose unsupported security type %d", choice)
+		}
+		if minor >= 8 {
+			if err := binary.Write(c.conn, binary.BigEndian, uint32(0)); err != nil {
+				return nil, 0, err
+			}
+		}
+	} else if err := binary.Write(c.conn, binary.BigEndian, uint32(securityNone)); err != nil {
+		return nil, 0, err
+	}
+
+	// the shared flag is ignored, every client shares the screen
+	if _, err := c.r.ReadByte(); err != nil {
+		return nil, 0, err
+	}
+
+	frame, seq := c.server.nextFrame(0)
+	if frame == nil {
+		return nil, 0, net.ErrClosed
+	}
+	size := frame.Rect.Size()
+	format := serverFormat.encode()
+	msg := binary.BigEndian.AppendUint16(nil, uint16(size.X))
+	msg = binary.BigEndian.AppendUint16(msg, uint16(size.Y))
+	msg = append(msg, format[:]...)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(c.server.options.Name)))
+	msg = append(msg, c.server.options.Name...)
+	if _, err := c.conn.Write(msg); err != nil {
+		return nil, 0, err
+	}
+	return frame, seq, nil
+}
+
+func (c *client) readMessages() error {
+	for {
+		msgType, err := c.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch msgType {
+		case msgSetPixelFormat:
+			var b [19]byte
+			if _, err := io.ReadFull(c.r, b[:]); err != nil {
+				return err
+			}
+			format, err := decodePixelFormat([16]byte(b[3:]))
+			if err != nil {
+				return err
+			}
+			c.mu.Lock()
+			c.format = format
+			c.mu.Unlock()
+		case msgSetEncodings:
+			var header [3]byte
+			if _, err := io.ReadFull(c.r, header[:]); err != nil {
+				return err
+			}
+			encodings := make([]int32, binary.BigEndian.Uint16(header[1:]))
+			if err := binary.Read(c.r, binary.BigEndian, encodings); err != nil {
+				return err
+			}
+			desktopSize := false
+			for _, e := range encodings {
+				if e == encodingDesktopSize {
+					desktopSize = true
+				}
+			}
+			c.mu.Lock()
+			c.desktopSize = desktopSize
+			c.mu.Unlock()
+		case msgFramebufferUpdateRequest:
+			var b [9]byte
+			if _, err := io.ReadFull(c.r, b[:]); err != nil {
+				return err
+			}
+			x, y := int(binary.BigEndian.Uint16(b[1:])), int(binary.BigEndian.Uint16(b[3:]))
+			w, h := int(binary.BigEndian.Uint16(b[5:])), int(binary.BigEndian.Uint16(b[7:]))
+			c.request(updateRequest{incremental: b[0] != 0, rect: image.Rect(x, y, x+w, y+h)})
+		case msgKeyEvent:
+			var b [7]byte
+			if _, err := io.ReadFull(c.r, b[:]); err != nil {
+				return err
+			}
+			c.key(b[0] != 0, binary.BigEndian.Uint32(b[3:]))
+		case msgPointerEvent:
+			var b [5]byte
+			if _, err := io.ReadFull(c.r, b[:]); err != nil {
+				return err
+			}
+			c.pointer(b[0], int(binary.BigEndian.Uint16(b[1:])), int(binary.BigEndian.Uint16(b[3:])))
+		case msgClientCutText:
+			var b [7]byte
+			if _, err := io.ReadFull(c.r, b[:]); err != nil {
+				return err
+			}
+			length := binary.BigEndian.Uint32(b[3:])
+			if length > maxCutText {
+				return fmt.Errorf("clipboard text of %d bytes is too long", length)
+			}
+			if _, err := c.r.Discard(int(length)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown message type %d", msgType)
+		}
+	}
+}
+
+// request queues an update request, merging it with one that has not been answered yet.
+func (c *client) request(req updateRequest) {
+	c.mu.Lock()
+	if c.pending == nil {
+		c.pending = &req
+	} else {
+		c.pending.rect = c.pending.rect.Union(req.rect)
+		c.pending.incremental = c.pending.incremental && req.incremental
+	}
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *client) key(down bool, sym uint32) {
+	key, ok := keysymKey(sym)
+	if !ok || c.server.options.ViewOnly {
+		return
+	}
+	evt := input.Event{Type: input.KeyRelease, Key: key}
+	if down {
+		evt.Type = input.KeyPress
+	}
+	c.keysDown[key] = down
+	banana.InjectEvent(evt)
+}
+
+func (c *client) pointer(mask uint8, x, y int) {
+	if c.server.options.ViewOnly {
+		return
+	}
+	if x != c.x || y != c.y {
+		c.x, c.y = x, y
+		banana.InjectEvent(input.Event{Type: input.MouseMove, X: x, Y: y})
+	}
+	for bit, button := range pointerButtons {
+		was, is := c.buttons&(1<<bit) != 0, mask&(1<<bit) != 0
+		if was == is {
+			continue
+		}
+		evt := input.Event{Type: input.MouseRelease, MouseButton: button, X: x, Y: y}
+		if is {
+			evt.Type = input.MousePress
+		}
+		banana.InjectEvent(evt)
+	}
+	c.buttons = mask
+}
+
+// releaseInput lets go of the keys and buttons a client held when it left.
+func (c *client) releaseInput() {
+	for key, down := range c.keysDown {
+		if down {
+			banana.InjectEvent(input.Event{Type: input.KeyRelease, Key: key})
+		}
+	}
+	c.pointer(0, c.x, c.y)
+}
+
+// writeUpdates answers update requests. Incremental requests wait for a frame that differs
+// from the last one sent within the requested area.
+func (c *client) writeUpdates(frame *image.RGBA, seq uint64) error {
+	w := bufio.NewWriterSize(c.conn, 64*1024)
+	size := frame.Rect.Size()
+	var sent *image.RGBA
+
+	for {
+		select {
+		case <-c.wake:
+		case <-c.done:
+			return nil
+		}
+		c.mu.Lock()
+		req := c.pending
+		c.pending = nil
+		c.mu.Unlock()
+		if req == nil {
+			continue
+		}
+
+		for {
+			if seq == 0 || (req.incremental && sent == frame) {
+				if frame, seq = c.server.nextFrame(seq); frame == nil {
+					return nil
+				}
+			}
+
+			var rects []image.Rectangle
+			var resized bool
+			c.mu.Lock()
+			format, desktopSize := c.format, c.desktopSize
+			c.mu.Unlock()
+			if frame.Rect.Size() != size && desktopSize {
+				size = frame.Rect.Size()
+				resized = true
+				rects = []image.Rectangle{image.Rectangle{Max: size}}
+			} else {
+				area := req.rect.Intersect(image.Rectangle{Max: size}).Intersect(frame.Rect)
+				if req.incremental && sent != nil && sent.Rect == frame.Rect {
+					area = changed(sent, frame, area)
+				}
+				if !area.Empty() {
+					rects = []image.Rectangle{area}
+				}
+			}
+			if len(rects) == 0 && req.incremental {
+				sent = frame
+				continue
+			}
+
+			if err := writeUpdate(w, frame, rects, resized, format); err != nil {
+				return err
+			}
+			sent = frame
+			break
+		}
+	}
+}
+
+// changed returns the part of area that differs between two frames of the same size.
+func changed(a, b *image.RGBA, area image.Rectangle) image.Rectangle {
+	var r image.Rectangle
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		start, end := a.PixOffset(area.Min.X, y), a.PixOffset(area.Max.X, y)
+		rowA, rowB := a.Pix[start:end], b.Pix[start:end]
+		if string(rowA) == string(rowB) {
+			continue
+		}
+		left, right := 0, len(rowA)/4
+		for left < right && string(rowA[left*4:left*4+4]) == string(rowB[left*4:left*4+4]) {
+			left++
+		}
+		for right > left && string(rowA[right*4-4:right*4]) == string(rowB[right*4-4:right*4]) {
+			right--
+		}
+		r = r.Union(image.Rect(area.Min.X+left, y, area.Min.X+right, y+1))
+	}
+	return r
+}
+
+// writeUpdate sends a FramebufferUpdate with the rects in raw encoding, preceded by
+// a DesktopSize rect when the frame changed size.
+func writeUpdate(w *bufio.Writer, frame *image.RGBA, rects []image.Rectangle, resized bool, format pixelFormat) error {
+	count := len(rects)
+	if resized {
+		count++
+	}
+	msg := []byte{0, 0}
+	msg = binary.BigEndian.AppendUint16(msg, uint16(count))
+	if resized {
+		size := frame.Rect.Size()
+		msg = appendRectHeader(msg, image.Rectangle{Max: size}, encodingDesktopSize)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	row := make([]byte, 0, frame.Rect.Dx()*format.bytesPerPixel())
+	for _, r := range rects {
+		if _, err := w.Write(appendRectHeader(nil, r, encodingRaw)); err != nil {
+			return err
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row = row[:0]
+			for x := r.Min.X; x < r.Max.X; x++ {
+				o := frame.PixOffset(x, y)
+				row = format.appendPixel(row, frame.Pix[o], frame.Pix[o+1], frame.Pix[o+2])
+			}
+			if _, err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
+
+func appendRectHeader(dst []byte, r image.Rectangle, encoding int32) []byte {
+	dst = binary.BigEndian.AppendUint16(dst, uint16(r.Min.X))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(r.Min.Y))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(r.Dx()))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(r.Dy()))
+	return binary.BigEndian.AppendUint32(dst, uint32(encoding))
+}
@@ -0,0 +1,29 @@
+package remote
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestChanged(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	b := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	all := a.Rect
+
+	if got := changed(a, b, all); !got.Empty() {
+		t.Errorf("changed() between equal frames = %v, want empty", got)
+	}
+
+	b.SetRGBA(2, 3, color.RGBA{R: 1})
+	b.SetRGBA(5, 6, color.RGBA{B: 1})
+	if got, want := changed(a, b, all), image.Rect(2, 3, 6, 7); got != want {
+		t.Errorf("changed() = %v, want %v", got, want)
+	}
+	if got, want := changed(a, b, image.Rect(4, 0, 8, 8)), image.Rect(5, 6, 6, 7); got != want {
+		t.Errorf("changed() within %v = %v, want %v", image.Rect(4, 0, 8, 8), got, want)
+	}
+	if got := changed(a, b, image.Rect(0, 0, 2, 8)); !got.Empty() {
+		t.Errorf("changed() outside of the changes = %v, want empty", got)
+	}
+}
@@ -0,0 +1,52 @@
+package remote
+
+import "github.com/dfirebaugh/banana/pkg/input"
+
+// keysyms maps the X11 keysyms of keys that do not type a character.
+var keysyms = map[uint32]input.Key{
+	0xff08: input.KeyBackspace,
+	0xff09: input.KeyTab,
+	0xff0d: input.KeyEnter,
+	0xff13: input.KeyPause,
+	0xff14: input.KeyScrollLock,
+	0xff1b: input.KeyEscape,
+	0xff50: input.KeyHome,
+	0xff51: input.KeyLeft,
+	0xff52: input.KeyUp,
+	0xff53: input.KeyRight,
+	0xff54: input.KeyDown,
+	0xff55: input.KeyPageUp,
+	0xff56: input.KeyPageDown,
+	0xff57: input.KeyEnd,
+	0xff61: input.KeyPrintScreen,
+	0xff63: input.KeyInsert,
+	0xff7f: input.KeyNumLock,
+	0xff8d: input.KeyKPEnter,
+	0xffe1: input.KeyLeftShift,
+	0xffe2: input.KeyRightShift,
+	0xffe3: input.KeyLeftControl,
+	0xffe4: input.KeyRightControl,
+	0xffe5: input.KeyCapsLock,
+	0xffe7: input.KeyLeftSuper,
+	0xffe8: input.KeyRightSuper,
+	0xffe9: input.KeyLeftAlt,
+	0xffea: input.KeyRightAlt,
+	0xffeb: input.KeyLeftSuper,
+	0xffec: input.KeyRightSuper,
+	0xffff: input.KeyDelete,
+}
+
+// keysymKey returns the key of an X11 keysym.
+func keysymKey(sym uint32) (input.Key, bool) {
+	switch {
+	case sym >= 0xffbe && sym <= 0xffc9:
+		return input.KeyF1 + input.Key(sym-0xffbe), true
+	case sym >= 0xffb0 && sym <= 0xffb9:
+		return input.KeyKP0 + input.Key(sym-0xffb0), true
+	case sym < 0x80:
+		// Latin-1 keysyms below 0x80 are ASCII
+		return input.KeyForChar(rune(sym))
+	}
+	key, ok := keysyms[sym]
+	return key, ok
+}
@@ -0,0 +1,37 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+func TestKeysymKey(t *testing.T) {
+	tests := []struct {
+		sym  uint32
+		want input.Key
+	}{
+		{'a', input.KeyA},
+		{'A', input.KeyA},
+		{'7', input.Key7},
+		{' ', input.KeySpace},
+		{0xffbe, input.KeyF1},
+		{0xffc9, input.KeyF12},
+		{0xffb0, input.KeyKP0},
+		{0xffb9, input.KeyKP9},
+		{0xff51, input.KeyLeft},
+		{0xff1b, input.KeyEscape},
+		{0xffe1, input.KeyLeftShift},
+	}
+	for _, tt := range tests {
+		if got, ok := keysymKey(tt.sym); !ok || got != tt.want {
+			t.Errorf("keysymKey(%#x) = %v, %v, want %v", tt.sym, got, ok, tt.want)
+		}
+	}
+
+	for _, sym := range []uint32{0xff00, 0xffca, 0x1000000} {
+		if got, ok := keysymKey(sym); ok {
+			t.Errorf("keysymKey(%#x) = %v, want no key", sym, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// pixelFormat is how a client wants pixels encoded. Only true color formats are supported.
+type pixelFormat struct {
+	BitsPerPixel, Depth             uint8
+	BigEndian, TrueColor            bool
+	RedMax, GreenMax, BlueMax       uint16
+	RedShift, GreenShift, BlueShift uint8
+}
+
+// serverFormat is the format announced to clients, 32-bit little endian BGRX.
+var serverFormat = pixelFormat{
+	BitsPerPixel: 32,
+	Depth:        24,
+	TrueColor:    true,
+	RedMax:       255,
+	GreenMax:     255,
+	BlueMax:      255,
+	RedShift:     16,
+	GreenShift:   8,
+	BlueShift:    0,
+}
+
+var errColorMap = errors.New("color map pixel formats are not supported")
+
+func decodePixelFormat(b [16]byte) (pixelFormat, error) {
+	pf := pixelFormat{
+		BitsPerPixel: b[0],
+		Depth:        b[1],
+		BigEndian:    b[2] != 0,
+		TrueColor:    b[3] != 0,
+		RedMax:       binary.BigEndian.Uint16(b[4:]),
+		GreenMax:     binary.BigEndian.Uint16(b[6:]),
+		BlueMax:      binary.BigEndian.Uint16(b[8:]),
+		RedShift:     b[10],
+		GreenShift:   b[11],
+		BlueShift:    b[12],
+	}
+	if !pf.TrueColor {
+		return pf, errColorMap
+	}
+	switch pf.BitsPerPixel {
+	case 8, 16, 32:
+	default:
+		return pf, errors.New("pixel formats must have 8, 16 or 32 bits per pixel")
+	}
+	return pf, nil
+}
+
+func (pf pixelFormat) encode() [16]byte {
+	var b [16]byte
+	b[0] = pf.BitsPerPixel
+	b[1] = pf.Depth
+	if pf.BigEndian {
+		b[2] = 1
+	}
+	if pf.TrueColor {
+		b[3] = 1
+	}
+	binary.BigEndian.PutUint16(b[4:], pf.RedMax)
+	binary.BigEndian.PutUint16(b[6:], pf.GreenMax)
+	binary.BigEndian.PutUint16(b[8:], pf.BlueMax)
+	b[10] = pf.RedShift
+	b[11] = pf.GreenShift
+	b[12] = pf.BlueShift
+	return b
+}
+
+// bytesPerPixel is the size of an encoded pixel.
+func (pf pixelFormat) bytesPerPixel() int {
+	return int(pf.BitsPerPixel) / 8
+}
+
+// appendPixel appends the encoding of a color with 8 bits per channel.
+func (pf pixelFormat) appendPixel(dst []byte, r, g, b uint8) []byte {
+	v := (uint32(r)*uint32(pf.RedMax)+127)/255<<pf.RedShift |
+		(uint32(g)*uint32(pf.GreenMax)+127)/255<<pf.GreenShift |
+		(uint32(b)*uint32(pf.BlueMax)+127)/255<<pf.BlueShift
+	switch pf.BitsPerPixel {
+	case 8:
+		return append(dst, uint8(v))
+	case 16:
+		if pf.BigEndian {
+			return binary.BigEndian.AppendUint16(dst, uint16(v))
+		}
+		return binary.LittleEndian.AppendUint16(dst, uint16(v))
+	default:
+		if pf.BigEndian {
+			return binary.BigEndian.AppendUint32(dst, v)
+		}
+		return binary.LittleEndian.AppendUint32(dst, v)
+	}
+}
@@ -0,0 +1,66 @@
+package remote
+
+import (
+	"bytes"
+	"testing"
+)
+
+var (
+	rgb565 = pixelFormat{
+		BitsPerPixel: 16, Depth: 16, TrueColor: true,
+		RedMax: 31, GreenMax: 63, BlueMax: 31,
+		RedShift: 11, GreenShift: 5, BlueShift: 0,
+	}
+	bgr233 = pixelFormat{
+		BitsPerPixel: 8, Depth: 8, TrueColor: true,
+		RedMax: 7, GreenMax: 7, BlueMax: 3,
+		RedShift: 0, GreenShift: 3, BlueShift: 6,
+	}
+)
+
+func TestAppendPixel(t *testing.T) {
+	rgb565BE := rgb565
+	rgb565BE.BigEndian = true
+
+	tests := []struct {
+		name    string
+		format  pixelFormat
+		r, g, b uint8
+		want    []byte
+	}{
+		{"32 bpp", serverFormat, 0x12, 0x34, 0x56, []byte{0x56, 0x34, 0x12, 0}},
+		{"16 bpp little endian", rgb565, 0, 0, 255, []byte{0x1f, 0}},
+		{"16 bpp big endian", rgb565BE, 255, 128, 0, []byte{0xfc, 0}},
+		{"8 bpp white", bgr233, 255, 255, 255, []byte{0xff}},
+		{"8 bpp rounds each channel", bgr233, 255, 0, 128, []byte{0x87}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.format.appendPixel([]byte{0xaa}, tt.r, tt.g, tt.b)
+			want := append([]byte{0xaa}, tt.want...)
+			if !bytes.Equal(got, want) {
+				t.Errorf("appendPixel(%d, %d, %d) = % x, want % x", tt.r, tt.g, tt.b, got, want)
+			}
+		})
+	}
+}
+
+func TestDecodePixelFormat(t *testing.T) {
+	for _, format := range []pixelFormat{serverFormat, rgb565, bgr233} {
+		got, err := decodePixelFormat(format.encode())
+		if err != nil || got != format {
+			t.Errorf("decodePixelFormat(%+v.encode()) = %+v, %v", format, got, err)
+		}
+	}
+
+	colorMap := bgr233
+	colorMap.TrueColor = false
+	if _, err := decodePixelFormat(colorMap.encode()); err != errColorMap {
+		t.Errorf("decoding a color map format returned %v, want %v", err, errColorMap)
+	}
+	odd := rgb565
+	odd.BitsPerPixel = 24
+	if _, err := decodePixelFormat(odd.encode()); err == nil {
+		t.Error("decoding a 24 bpp format succeeded")
+	}
+}
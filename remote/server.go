@@ -0,0 +1,155 @@
+// Package remote serves the frames rendered by banana over the RFB protocol used by VNC,
+// and feeds the keys and pointer of connected clients to the engine as input.
+// It lets a standard VNC client watch and drive a kiosk build or a headless instance.
+//
+//	server, err := remote.Serve("127.0.0.1:5900", remote.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer server.Close()
+//	banana.Run(update, render)
+//
+// Frames are only read back from the GPU while a client is waiting for an update.
+package remote
+
+import (
+	"errors"
+	"image"
+	"net"
+	"sync"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/graphics/diag"
+)
+
+// Options configure a Server.
+type Options struct {
+	// Name is the desktop name shown by clients. It defaults to "banana".
+	Name string
+	// ViewOnly ignores the keys and pointer of clients.
+	ViewOnly bool
+}
+
+// Server accepts RFB clients. Every client sees the same frames.
+type Server struct {
+	listener net.Listener
+	options  Options
+	// removeHook unregisters frameDrawn from the engine.
+	removeHook func()
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	frame   *image.RGBA
+	seq     uint64
+	waiting int
+	clients map[*client]bool
+	closed  bool
+}
+
+// Serve listens on addr, such as "127.0.0.1:5900", and starts accepting clients.
+// It can be called before banana.Run or from any goroutine while the game runs.
+// Clients are not authenticated, so listen on loopback or a trusted network.
+func Serve(addr string, options Options) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if options.Name == "" {
+		options.Name = "banana"
+	}
+
+	s := &Server{
+		listener: listener,
+		options:  options,
+		clients:  make(map[*client]bool),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.removeHook = banana.OnFrameDrawn(s.frameDrawn)
+	go s.accept()
+	diag.Infof("Serving RFB on %s", listener.Addr())
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops listening, disconnects every client and stops reading frames back.
+func (s *Server) Close() error {
+	s.removeHook()
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	return s.listener.Close()
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				diag.Errorf("RFB server stopped accepting clients: %v", err)
+			}
+			return
+		}
+
+		c := newClient(s, conn)
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.clients[c] = true
+		s.mu.Unlock()
+
+		go func() {
+			if err := c.serve(); err != nil {
+				diag.Warnf("RFB client %s disconnected: %v", conn.RemoteAddr(), err)
+			} else {
+				diag.Infof("RFB client %s disconnected", conn.RemoteAddr())
+			}
+			s.mu.Lock()
+			delete(s.clients, c)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// frameDrawn reads the frame back when a client is waiting for one. It runs on the game's goroutine.
+func (s *Server) frameDrawn() {
+	s.mu.Lock()
+	waiting := s.waiting > 0
+	s.mu.Unlock()
+	if !waiting {
+		return
+	}
+
+	frame := banana.ReadScreen()
+	s.mu.Lock()
+	s.frame = frame
+	s.seq++
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// nextFrame blocks until a frame newer than after has been drawn and returns it with its sequence number.
+// It returns a nil frame when the server is closed.
+func (s *Server) nextFrame(after uint64) (*image.RGBA, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waiting++
+	defer func() { s.waiting-- }()
+	for s.seq <= after && !s.closed {
+		s.cond.Wait()
+	}
+	if s.closed {
+		return nil, 0
+	}
+	return s.frame, s.seq
+}
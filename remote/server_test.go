@@ -0,0 +1,251 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dfirebaugh/banana"
+	"github.com/dfirebaugh/banana/graphics/software"
+	"github.com/dfirebaugh/banana/pkg/fb"
+	"github.com/dfirebaugh/banana/pkg/input"
+)
+
+const (
+	screenWidth  = 32
+	screenHeight = 24
+)
+
+var clearColor = color.RGBA{0x20, 0x40, 0x80, 0xff}
+
+// gameCalls runs functions on the goroutine that runs the engine, which owns the input state.
+var gameCalls = make(chan func())
+
+func TestMain(m *testing.M) {
+	banana.SetBackend(software.New(fb.New(screenWidth, screenHeight)))
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		banana.Run(func() {
+			for {
+				select {
+				case fn := <-gameCalls:
+					fn()
+				default:
+					return
+				}
+			}
+		}, func() {
+			banana.Clear(clearColor)
+		})
+	}()
+
+	code := m.Run()
+	onGame(banana.Close)
+	<-stopped
+	os.Exit(code)
+}
+
+// onGame runs fn during the next update and waits for it to return.
+func onGame(fn func()) {
+	done := make(chan struct{})
+	gameCalls <- func() {
+		fn()
+		close(done)
+	}
+	<-done
+}
+
+// waitFor polls cond on the game's goroutine until it holds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var ok bool
+		onGame(func() { ok = cond() })
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func serve(t *testing.T, options Options) *Server {
+	t.Helper()
+	server, err := Serve("127.0.0.1:0", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// rfbClient is the client side of the protocol, just enough to drive a Server.
+type rfbClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, server *Server) *rfbClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+	return &rfbClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *rfbClient) read(n int) []byte {
+	c.t.Helper()
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		c.t.Fatal(err)
+	}
+	return b
+}
+
+func (c *rfbClient) write(b ...byte) {
+	c.t.Helper()
+	if _, err := c.conn.Write(b); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// handshake speaks the given minor version of RFB 3 up to ServerInit and returns its fields.
+func (c *rfbClient) handshake(minor int) (width, height int, format pixelFormat, name string) {
+	c.t.Helper()
+	if got := string(c.read(12)); got != "RFB 003.008\n" {
+		c.t.Fatalf("server version = %q", got)
+	}
+	c.write([]byte(fmt.Sprintf("RFB 003.%03d\n", minor))...)
+
+	if minor >= 7 {
+		count := c.read(1)[0]
+		if types := c.read(int(count)); string(types) != string([]byte{securityNone}) {
+			c.t.Fatalf("security types = %v, want [%d]", types, securityNone)
+		}
+		c.write(securityNone)
+		if minor >= 8 {
+			if result := binary.BigEndian.Uint32(c.read(4)); result != 0 {
+				c.t.Fatalf("security result = %d, want 0", result)
+			}
+		}
+	} else if security := binary.BigEndian.Uint32(c.read(4)); security != securityNone {
+		c.t.Fatalf("security type = %d, want %d", security, securityNone)
+	}
+
+	c.write(1) // shared
+	init := c.read(24)
+	width = int(binary.BigEndian.Uint16(init[0:]))
+	height = int(binary.BigEndian.Uint16(init[2:]))
+	format, err := decodePixelFormat([16]byte(init[4:20]))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	name = string(c.read(int(binary.BigEndian.Uint32(init[20:]))))
+	return width, height, format, name
+}
+
+func TestHandshake(t *testing.T) {
+	server := serve(t, Options{Name: "test"})
+	for _, minor := range []int{3, 7, 8} {
+		t.Run(fmt.Sprintf("3.%d", minor), func(t *testing.T) {
+			c := dial(t, server)
+			width, height, format, name := c.handshake(minor)
+			if width != screenWidth || height != screenHeight {
+				t.Errorf("ServerInit size = %dx%d, want %dx%d", width, height, screenWidth, screenHeight)
+			}
+			if format != serverFormat {
+				t.Errorf("ServerInit format = %+v, want %+v", format, serverFormat)
+			}
+			if name != "test" {
+				t.Errorf("ServerInit name = %q, want %q", name, "test")
+			}
+		})
+	}
+}
+
+func TestRawUpdate(t *testing.T) {
+	c := dial(t, serve(t, Options{}))
+	c.handshake(8)
+
+	// a non-incremental request for the whole screen
+	request := []byte{msgFramebufferUpdateRequest, 0}
+	request = binary.BigEndian.AppendUint16(request, 0)
+	request = binary.BigEndian.AppendUint16(request, 0)
+	request = binary.BigEndian.AppendUint16(request, screenWidth)
+	request = binary.BigEndian.AppendUint16(request, screenHeight)
+	c.write(request...)
+
+	header := c.read(4)
+	if header[0] != 0 || binary.BigEndian.Uint16(header[2:]) != 1 {
+		t.Fatalf("update header = % x, want a FramebufferUpdate with one rect", header)
+	}
+	rect := c.read(12)
+	x, y := binary.BigEndian.Uint16(rect[0:]), binary.BigEndian.Uint16(rect[2:])
+	w, h := binary.BigEndian.Uint16(rect[4:]), binary.BigEndian.Uint16(rect[6:])
+	if x != 0 || y != 0 || w != screenWidth || h != screenHeight {
+		t.Fatalf("update rect = %d,%d %dx%d, want the whole screen", x, y, w, h)
+	}
+	if encoding := int32(binary.BigEndian.Uint32(rect[8:])); encoding != encodingRaw {
+		t.Fatalf("update encoding = %d, want raw", encoding)
+	}
+
+	pixels := c.read(screenWidth * screenHeight * 4)
+	want := serverFormat.appendPixel(nil, clearColor.R, clearColor.G, clearColor.B)
+	for i := 0; i < len(pixels); i += 4 {
+		if string(pixels[i:i+4]) != string(want) {
+			t.Fatalf("pixel %d = % x, want % x", i/4, pixels[i:i+4], want)
+		}
+	}
+}
+
+func TestInputIsInjected(t *testing.T) {
+	c := dial(t, serve(t, Options{}))
+	c.handshake(8)
+
+	c.write(msgKeyEvent, 1, 0, 0, 0, 0, 0, 'a')
+	waitFor(t, "the key to be pressed", func() bool { return banana.IsKeyPressed(input.KeyA) })
+	c.write(msgKeyEvent, 0, 0, 0, 0, 0, 0, 'a')
+	waitFor(t, "the key to be released", func() bool { return !banana.IsKeyPressed(input.KeyA) })
+
+	c.write(msgPointerEvent, 1, 0, 5, 0, 6)
+	waitFor(t, "the left button to be pressed", func() bool {
+		x, y := banana.GetCursorPosition()
+		return banana.IsButtonPressed(input.MouseButtonLeft) && x == 5 && y == 6
+	})
+
+	// leaving releases the button
+	c.conn.Close()
+	waitFor(t, "the left button to be released", func() bool { return !banana.IsButtonPressed(input.MouseButtonLeft) })
+}
+
+func TestCloseRemovesFrameHook(t *testing.T) {
+	server := serve(t, Options{})
+	server.Close()
+
+	// pretend a client is waiting so that a registered hook would read frames back
+	server.mu.Lock()
+	server.waiting++
+	server.mu.Unlock()
+	onGame(func() {})
+	onGame(func() {})
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.seq != 0 {
+		t.Errorf("a closed server read back %d frames", server.seq)
+	}
+}
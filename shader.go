@@ -0,0 +1,49 @@
+package banana
+
+import "github.com/dfirebaugh/banana/graphics"
+
+// Shader is a GPU program compiled from GLSL.
+type Shader graphics.Shader
+
+// NewShader compiles a shader from GLSL source.
+//
+// An empty vertexSource selects a vertex shader that covers the screen, which is what post processing wants.
+// Its fragment shader receives:
+//
+//	in vec2 v_uv;               // 0,0 at the bottom-left of the screen
+//	uniform sampler2D u_texture; // the output of the previous pass
+//	uniform vec2 u_resolution;   // size of the screen in pixels
+//	uniform float u_time;        // seconds since post processing was enabled
+func NewShader(vertexSource, fragmentSource string) (Shader, error) {
+	ensureSetupCompletion()
+	return banana.graphicsBackend.NewShader(vertexSource, fragmentSource)
+}
+
+// SetPostProcess runs every frame through the given shaders, in order, before it is shown.
+// Calling it without shaders turns post processing off.
+func SetPostProcess(shaders ...Shader) {
+	ensureSetupCompletion()
+	list := make([]graphics.Shader, len(shaders))
+	for i, s := range shaders {
+		list[i] = s
+	}
+	banana.graphicsBackend.SetPostProcess(list)
+}
+
+// NewShaderFromFiles compiles a shader from GLSL files. An empty vertexPath selects the full-screen vertex shader.
+// The shader is recompiled when its files change once EnableShaderHotReload has been called.
+func NewShaderFromFiles(vertexPath, fragmentPath string) (Shader, error) {
+	ensureSetupCompletion()
+	return banana.graphicsBackend.NewShaderFromFiles(vertexPath, fragmentPath)
+}
+
+// EnableShaderHotReload is a development mode that recompiles shaders when their files are saved.
+// Shaders from NewShaderFromFiles are watched. When dir is not empty, primitive.vert and primitive.frag
+// in dir replace the built-in shader and are watched too.
+//
+// A shader that fails to compile keeps running its last good version, and the compile log
+// is logged and shown in a banner at the top of the window until the error is fixed.
+func EnableShaderHotReload(dir string) error {
+	ensureSetupCompletion()
+	return banana.graphicsBackend.EnableShaderHotReload(dir)
+}
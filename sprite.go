@@ -0,0 +1,102 @@
+package banana
+
+import (
+	"image/color"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+// Drawable is anything that can draw itself during the render callback.
+type Drawable interface {
+	Render()
+}
+
+// RenderAll draws every drawable in order.
+func RenderAll(drawables ...Drawable) {
+	for _, d := range drawables {
+		d.Render()
+	}
+}
+
+// Sprite draws a texture region with a transform.
+// Create sprites with NewSprite: a zero Alpha is transparent, so a Sprite literal that leaves it out draws nothing.
+type Sprite struct {
+	Region Region
+	// X and Y position the pivot on screen.
+	X, Y float32
+	// ScaleX and ScaleY size the sprite relative to its region. The zero value is treated as 1.
+	ScaleX, ScaleY float32
+	// Rotation turns the sprite around its pivot, in radians.
+	Rotation float32
+	// PivotX and PivotY are the normalized point of the sprite that is placed at X, Y and rotated around.
+	// (0, 0) is the top-left corner and (0.5, 0.5) is the center.
+	PivotX, PivotY float32
+	FlipX, FlipY   bool
+	// Tint is multiplied with every texel. A nil Tint leaves the sprite unchanged.
+	Tint color.Color
+	// Alpha is the opacity of the sprite, from 0, transparent, to 1, opaque.
+	// NewSprite starts sprites at 1.
+	Alpha float32
+}
+
+// NewSprite returns a sprite of a region at its natural size.
+func NewSprite(region Region) *Sprite {
+	return &Sprite{Region: region, ScaleX: 1, ScaleY: 1, Alpha: 1}
+}
+
+func (s *Sprite) scale() (float32, float32) {
+	scaleX, scaleY := s.ScaleX, s.ScaleY
+	if scaleX == 0 {
+		scaleX = 1
+	}
+	if scaleY == 0 {
+		scaleY = 1
+	}
+	return scaleX, scaleY
+}
+
+// Size returns the size of the sprite on screen. For a trimmed region it is the size of the untrimmed frame.
+func (s *Sprite) Size() (float32, float32) {
+	scaleX, scaleY := s.scale()
+	return s.Region.sourceWidth * scaleX, s.Region.sourceHeight * scaleY
+}
+
+func (s *Sprite) Render() {
+	ensureSetupCompletion()
+	r := s.Region
+	scaleX, scaleY := s.scale()
+
+	// the pivot is given relative to the untrimmed frame, the quad only covers the trimmed region
+	offsetX, offsetY := r.offsetX, r.offsetY
+	if s.FlipX {
+		offsetX = r.sourceWidth - r.offsetX - r.rectWidth
+	}
+	if s.FlipY {
+		offsetY = r.sourceHeight - r.offsetY - r.rectHeight
+	}
+	originX, originY := s.PivotX, s.PivotY
+	if r.rectWidth != 0 {
+		originX = (s.PivotX*r.sourceWidth - offsetX) / r.rectWidth
+	}
+	if r.rectHeight != 0 {
+		originY = (s.PivotY*r.sourceHeight - offsetY) / r.rectHeight
+	}
+
+	alpha := s.Alpha
+	options := &graphics.TextureRenderOptions{
+		X:             s.X,
+		Y:             s.Y,
+		DesiredWidth:  r.rectWidth * scaleX,
+		DesiredHeight: r.rectHeight * scaleY,
+		Scale:         1,
+		FlipX:         s.FlipX,
+		FlipY:         s.FlipY,
+		Rotation:      s.Rotation,
+		Tint:          s.Tint,
+		Alpha:         &alpha,
+		OriginX:       originX,
+		OriginY:       originY,
+	}
+	r.apply(options)
+	banana.graphicsBackend.RenderTexture(r.texture, options)
+}
@@ -0,0 +1,48 @@
+package banana
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/dfirebaugh/banana/graphics/software"
+	"github.com/dfirebaugh/banana/pkg/fb"
+)
+
+func TestMain(m *testing.M) {
+	SetBackend(software.New(fb.New(32, 32)))
+	os.Exit(m.Run())
+}
+
+func TestSpriteAlpha(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	region := NewRegion(UploadTexture(img), 0, 0, 4, 4)
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	faded := NewSprite(region)
+	faded.Alpha = 0
+
+	tests := []struct {
+		name   string
+		sprite *Sprite
+		want   color.RGBA
+	}{
+		{"NewSprite is opaque", NewSprite(region), white},
+		{"zero value is transparent", &Sprite{Region: region, ScaleX: 1, ScaleY: 1}, black},
+		{"faded out", faded, black},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Clear(black)
+			tt.sprite.X, tt.sprite.Y = 8, 8
+			tt.sprite.Render()
+			if got := ReadScreen().RGBAAt(9, 9); got != tt.want {
+				t.Errorf("pixel under the sprite = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package banana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "image/png"
+)
+
+// defaultFrameDuration is used for frames whose file gives no duration, matching Aseprite's default.
+const defaultFrameDuration = 100 * time.Millisecond
+
+// SheetFrame is one frame of a sprite sheet exported by a packing tool.
+type SheetFrame struct {
+	Name   string
+	Region Region
+	// Duration is how long the frame is shown in animations.
+	Duration time.Duration
+	// PivotX and PivotY are the normalized pivot of the frame.
+	PivotX, PivotY float32
+}
+
+// SpriteSheet is a texture with the named frames, animations and slices described by its JSON sidecar file.
+type SpriteSheet struct {
+	Texture    uint32
+	Frames     []SheetFrame
+	Animations map[string]*Animation
+	Slices     map[string]Slice
+
+	frames map[string]int
+}
+
+// Frame returns the frame with the given name.
+func (s *SpriteSheet) Frame(name string) (SheetFrame, bool) {
+	i, ok := s.frames[name]
+	if !ok {
+		return SheetFrame{}, false
+	}
+	return s.Frames[i], true
+}
+
+// Region returns the region of the frame with the given name.
+func (s *SpriteSheet) Region(name string) (Region, bool) {
+	frame, ok := s.Frame(name)
+	return frame.Region, ok
+}
+
+// NewSprite returns a sprite of the named frame with the frame's pivot.
+func (s *SpriteSheet) NewSprite(name string) (*Sprite, bool) {
+	frame, ok := s.Frame(name)
+	if !ok {
+		return nil, false
+	}
+	sprite := NewSprite(frame.Region)
+	sprite.PivotX, sprite.PivotY = frame.PivotX, frame.PivotY
+	return sprite, true
+}
+
+// NewAnimationPlayer returns a player for sprite that can play every animation of the sheet.
+func (s *SpriteSheet) NewAnimationPlayer(sprite *Sprite) *AnimationPlayer {
+	player := NewAnimationPlayer(sprite)
+	for _, a := range s.Animations {
+		player.Add(a)
+	}
+	return player
+}
+
+func (s *SpriteSheet) addFrame(frame SheetFrame) {
+	s.frames[frame.Name] = len(s.Frames)
+	s.Frames = append(s.Frames, frame)
+}
+
+// animation returns an animation of the frames at the given indices.
+func (s *SpriteSheet) animation(name string, indices []int, mode AnimationMode) (*Animation, error) {
+	a := &Animation{Name: name, Mode: mode}
+	for _, i := range indices {
+		if i < 0 || i >= len(s.Frames) {
+			return nil, fmt.Errorf("animation %q refers to frame %d of %d", name, i, len(s.Frames))
+		}
+		a.Frames = append(a.Frames, AnimationFrame{Region: s.Frames[i].Region, Duration: s.Frames[i].Duration})
+	}
+	return a, nil
+}
+
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+func (r jsonRect) rectangle() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+type jsonSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// jsonFrame is a frame in the JSON format TexturePacker introduced and Aseprite also writes.
+type jsonFrame struct {
+	Filename         string                  `json:"filename"`
+	Frame            jsonRect                `json:"frame"`
+	Rotated          bool                    `json:"rotated"`
+	Trimmed          bool                    `json:"trimmed"`
+	SpriteSourceSize jsonRect                `json:"spriteSourceSize"`
+	SourceSize       jsonSize                `json:"sourceSize"`
+	Duration         int                     `json:"duration"`
+	Pivot            *struct{ X, Y float32 } `json:"pivot"`
+}
+
+// jsonFrames decodes frames written either as an array or as an object keyed by name.
+// The order of an object is kept because tags refer to frames by their position.
+type jsonFrames []jsonFrame
+
+func (f *jsonFrames) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]jsonFrame)(f))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var frame jsonFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return err
+		}
+		frame.Filename = token.(string)
+		*f = append(*f, frame)
+	}
+	return nil
+}
+
+type jsonSheetMeta struct {
+	Image string   `json:"image"`
+	Size  jsonSize `json:"size"`
+}
+
+// newSpriteSheet uploads the sheet image, creates a frame for every frame of the file and then calls build
+// to add what else the file describes. The image is deleted again when build fails.
+func newSpriteSheet(img image.Image, frames jsonFrames, build func(sheet *SpriteSheet) error) (*SpriteSheet, error) {
+	ensureSetupCompletion()
+	bounds := img.Bounds()
+	for _, f := range frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("frame %q is rotated in the sheet, which is not supported; disable rotation when exporting", f.Filename)
+		}
+		if !f.Frame.rectangle().In(image.Rect(0, 0, bounds.Dx(), bounds.Dy())) {
+			return nil, fmt.Errorf("frame %q lies outside of the %dx%d sheet", f.Filename, bounds.Dx(), bounds.Dy())
+		}
+	}
+
+	sheet := &SpriteSheet{
+		Texture:    UploadTexture(img),
+		Animations: make(map[string]*Animation),
+		Slices:     make(map[string]Slice),
+		frames:     make(map[string]int),
+	}
+	for _, f := range frames {
+		region := NewRegionFromRect(sheet.Texture, f.Frame.rectangle())
+		if f.Trimmed {
+			region = region.Trimmed(f.SpriteSourceSize.X, f.SpriteSourceSize.Y, f.SourceSize.W, f.SourceSize.H)
+		}
+		frame := SheetFrame{
+			Name:     f.Filename,
+			Region:   region,
+			Duration: time.Duration(f.Duration) * time.Millisecond,
+		}
+		if frame.Duration <= 0 {
+			frame.Duration = defaultFrameDuration
+		}
+		if f.Pivot != nil {
+			frame.PivotX, frame.PivotY = f.Pivot.X, f.Pivot.Y
+		}
+		sheet.addFrame(frame)
+	}
+	if err := build(sheet); err != nil {
+		DeleteTexture(sheet.Texture)
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// loadSheetFile reads a JSON sidecar file and decodes the image it names, which is relative to the JSON file.
+func loadSheetFile(path string, parse func(data []byte, img image.Image) (*SpriteSheet, error)) (*SpriteSheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Meta jsonSheetMeta `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Meta.Image == "" {
+		return nil, fmt.Errorf("%s does not name its image", path)
+	}
+
+	imagePath := filepath.Join(filepath.Dir(path), filepath.FromSlash(file.Meta.Image))
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", imagePath, err)
+	}
+
+	sheet, err := parse(data, img)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sheet, nil
+}
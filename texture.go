@@ -2,6 +2,7 @@ package banana
 
 import (
 	"image"
+	"image/color"
 
 	"github.com/dfirebaugh/banana/graphics"
 )
@@ -10,10 +11,52 @@ func UploadTexture(img image.Image) uint32 {
 	return banana.graphicsBackend.UploadTexture(img)
 }
 
+// TextureFilter selects how a texture is sampled when it is scaled.
+type TextureFilter int
+
+const (
+	FilterNearest TextureFilter = TextureFilter(graphics.FilterNearest)
+	FilterLinear  TextureFilter = TextureFilter(graphics.FilterLinear)
+)
+
+type TextureOptions struct {
+	Filter TextureFilter
+	// Mipmaps generates a mipmap chain so the texture stays smooth when drawn smaller than its size.
+	Mipmaps bool
+	// Standalone stores the texture in its own GPU texture instead of the shared atlas.
+	// Textures that are large, linearly filtered or mipmapped are always standalone.
+	Standalone bool
+}
+
+// UploadTextureWithOptions uploads an image with its own filtering and mipmap settings.
+// Photos and UI art that are drawn scaled usually want FilterLinear with Mipmaps.
+func UploadTextureWithOptions(img image.Image, options TextureOptions) uint32 {
+	ensureSetupCompletion()
+	return banana.graphicsBackend.UploadTextureWithOptions(img, graphics.TextureOptions{
+		Filter:     graphics.TextureFilter(options.Filter),
+		Mipmaps:    options.Mipmaps,
+		Standalone: options.Standalone,
+	})
+}
+
 func UpdateTexture(textureID uint32, img image.Image, xOffset, yOffset float32) {
 	banana.graphicsBackend.UpdateTexture(textureID, img, int(xOffset), int(yOffset))
 }
 
+// DeleteTexture frees a texture. Space it used in the atlas is reused by later uploads.
+// Handles are never reused, so a deleted handle can not alias a newer texture.
+func DeleteTexture(textureID uint32) {
+	ensureSetupCompletion()
+	banana.graphicsBackend.DeleteTexture(textureID)
+}
+
+// CompactAtlas repacks the texture atlas to reclaim space fragmented by deleted textures.
+// Existing handles stay valid. It is best called while loading rather than every frame.
+func CompactAtlas() {
+	ensureSetupCompletion()
+	banana.graphicsBackend.CompactAtlas()
+}
+
 type TextureRenderOptions struct {
 	TextureIndex                int
 	X, Y                        float32
@@ -24,36 +67,29 @@ type TextureRenderOptions struct {
 	Width, Height               float32
 	FlipX, FlipY                bool
 	Rotation                    float32
-}
 
-func RenderTexture(textureHandle uint32, options *TextureRenderOptions) {
-	ensureSetupCompletion()
-	banana.graphicsBackend.RenderTexture(
-		textureHandle,
-		&graphics.TextureRenderOptions{
-			TextureIndex:  float32(options.TextureIndex),
-			X:             options.X,
-			Y:             options.Y,
-			RectWidth:     options.RectWidth,
-			RectHeight:    options.RectHeight,
-			DesiredWidth:  options.DesiredWidth,
-			DesiredHeight: options.DesiredHeight,
-			Scale:         options.Scale,
-			RectX:         options.RectX,
-			RectY:         options.RectY,
-			Width:         options.Width,
-			Height:        options.Height,
-			FlipX:         options.FlipX,
-			FlipY:         options.FlipY,
-			Rotation:      options.Rotation,
-		})
+	// Tint is multiplied with every texel. A nil Tint leaves the texture unchanged.
+	Tint color.Color
+	// Alpha scales the opacity of the texture from 0, transparent, to 1.
+	// A nil Alpha leaves the texture fully opaque.
+	Alpha *float32
+	// OriginX and OriginY are the normalized pivot of the texture.
+	// (0, 0) is the top-left corner and (0.5, 0.5) is the center.
+	// X and Y position the pivot on screen and Rotation and skew are applied around it.
+	OriginX, OriginY float32
+	// SkewX and SkewY shear the texture along each axis, in radians.
+	SkewX, SkewY float32
 }
 
-type Framebuffer graphics.Framebuffer
+// Opacity returns a for the Alpha of render options, so a fade can be written inline:
+//
+//	Alpha: banana.Opacity(fade),
+func Opacity(a float32) *float32 {
+	return &a
+}
 
-func RenderFramebuffer(fb Framebuffer, options *TextureRenderOptions) {
-	ensureSetupCompletion()
-	graphicsOptions := &graphics.TextureRenderOptions{
+func (options *TextureRenderOptions) toGraphicsOptions() *graphics.TextureRenderOptions {
+	return &graphics.TextureRenderOptions{
 		TextureIndex:  float32(options.TextureIndex),
 		X:             options.X,
 		Y:             options.Y,
@@ -69,8 +105,25 @@ func RenderFramebuffer(fb Framebuffer, options *TextureRenderOptions) {
 		FlipX:         options.FlipX,
 		FlipY:         options.FlipY,
 		Rotation:      options.Rotation,
+		Tint:          options.Tint,
+		Alpha:         options.Alpha,
+		OriginX:       options.OriginX,
+		OriginY:       options.OriginY,
+		SkewX:         options.SkewX,
+		SkewY:         options.SkewY,
 	}
-	banana.graphicsBackend.RenderFramebuffer(fb, graphicsOptions)
+}
+
+func RenderTexture(textureHandle uint32, options *TextureRenderOptions) {
+	ensureSetupCompletion()
+	banana.graphicsBackend.RenderTexture(textureHandle, options.toGraphicsOptions())
+}
+
+type Framebuffer graphics.Framebuffer
+
+func RenderFramebuffer(fb Framebuffer, options *TextureRenderOptions) {
+	ensureSetupCompletion()
+	banana.graphicsBackend.RenderFramebuffer(fb, options.toGraphicsOptions())
 }
 
 func AddFramebuffer(width, height int) (Framebuffer, error) {
@@ -78,6 +131,35 @@ func AddFramebuffer(width, height int) (Framebuffer, error) {
 	return banana.graphicsBackend.AddFramebuffer(width, height)
 }
 
+// FramebufferFormat is the storage format of a framebuffer's color texture.
+type FramebufferFormat int
+
+const (
+	FramebufferRGBA8   FramebufferFormat = FramebufferFormat(graphics.FramebufferRGBA8)
+	FramebufferRGBA16F FramebufferFormat = FramebufferFormat(graphics.FramebufferRGBA16F)
+	FramebufferRGBA32F FramebufferFormat = FramebufferFormat(graphics.FramebufferRGBA32F)
+	FramebufferR8      FramebufferFormat = FramebufferFormat(graphics.FramebufferR8)
+	FramebufferR16F    FramebufferFormat = FramebufferFormat(graphics.FramebufferR16F)
+	FramebufferR32F    FramebufferFormat = FramebufferFormat(graphics.FramebufferR32F)
+)
+
+type FramebufferOptions struct {
+	// Samples enables multisample anti-aliasing when greater than 1, for example 4.
+	// The framebuffer is resolved automatically before it is sampled.
+	Samples int
+	// Format selects 8 bit, half float or float storage. Single channel formats are sampled as grayscale.
+	Format FramebufferFormat
+}
+
+// AddFramebufferWithOptions creates a framebuffer with multisampling or a non default color format.
+func AddFramebufferWithOptions(width, height int, options FramebufferOptions) (Framebuffer, error) {
+	ensureSetupCompletion()
+	return banana.graphicsBackend.AddFramebufferWithOptions(width, height, graphics.FramebufferOptions{
+		Samples: options.Samples,
+		Format:  graphics.FramebufferFormat(options.Format),
+	})
+}
+
 func ResizeFramebuffer(fb Framebuffer, width, height int) {
 	fb.Resize(width, height)
 }
@@ -0,0 +1,93 @@
+package banana
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type texturePackerFile struct {
+	Frames jsonFrames `json:"frames"`
+	// Animations is written by exporters for engines such as PixiJS, listing frame names by animation.
+	Animations map[string][]string `json:"animations"`
+}
+
+// LoadTexturePacker loads a sheet exported by TexturePacker in the JSON hash or JSON array format.
+// The image is read from the path the JSON file names relative to itself.
+func LoadTexturePacker(path string) (*SpriteSheet, error) {
+	return loadSheetFile(path, ParseTexturePacker)
+}
+
+// ParseTexturePacker creates a sprite sheet from the JSON data TexturePacker exported with img.
+// Frame pivots are kept when pivot points are enabled in TexturePacker.
+//
+// Animations listed in the file are used as they are. Otherwise frames with numbered names,
+// such as "run_01.png" and "run_02.png", become a looping animation named "run".
+// TexturePacker stores no timing, so every frame is shown for 100ms; change the frames' Duration to adjust.
+func ParseTexturePacker(data []byte, img image.Image) (*SpriteSheet, error) {
+	var file texturePackerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	return newSpriteSheet(img, file.Frames, func(sheet *SpriteSheet) error {
+		animations := file.Animations
+		if len(animations) == 0 {
+			animations = numberedAnimations(sheet.Frames)
+		}
+		for name, frameNames := range animations {
+			indices := make([]int, len(frameNames))
+			for i, frameName := range frameNames {
+				index, ok := sheet.frames[frameName]
+				if !ok {
+					return fmt.Errorf("animation %q refers to unknown frame %q", name, frameName)
+				}
+				indices[i] = index
+			}
+			a, err := sheet.animation(name, indices, AnimationLoop)
+			if err != nil {
+				return err
+			}
+			sheet.Animations[name] = a
+		}
+		return nil
+	})
+}
+
+// numberedAnimations groups frames whose names end in a number by the rest of their name, in numeric order.
+func numberedAnimations(frames []SheetFrame) map[string][]string {
+	type numberedFrame struct {
+		name   string
+		number int
+	}
+	groups := make(map[string][]numberedFrame)
+	for _, frame := range frames {
+		base := strings.TrimSuffix(frame.Name, path.Ext(frame.Name))
+		prefix := strings.TrimRight(base, "0123456789")
+		number, err := strconv.Atoi(base[len(prefix):])
+		if err != nil {
+			continue
+		}
+		prefix = strings.TrimRight(prefix, "_- .")
+		if prefix == "" {
+			continue
+		}
+		groups[prefix] = append(groups[prefix], numberedFrame{frame.Name, number})
+	}
+
+	animations := make(map[string][]string)
+	for name, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].number < group[j].number })
+		for _, frame := range group {
+			animations[name] = append(animations[name], frame.name)
+		}
+	}
+	return animations
+}
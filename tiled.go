@@ -0,0 +1,55 @@
+package banana
+
+import (
+	"image/color"
+
+	"github.com/dfirebaugh/banana/graphics"
+)
+
+// WrapMode controls how a tiled texture repeats.
+type WrapMode int
+
+const (
+	WrapRepeat WrapMode = WrapMode(graphics.WrapRepeat)
+	WrapMirror WrapMode = WrapMode(graphics.WrapMirror)
+	WrapClamp  WrapMode = WrapMode(graphics.WrapClamp)
+)
+
+type TiledTextureRenderOptions struct {
+	X, Y          float32
+	Width, Height float32
+	// RectX, RectY, RectWidth and RectHeight select the region of the texture that is tiled.
+	// A zero sized rect tiles the whole texture.
+	RectX, RectY          float32
+	RectWidth, RectHeight float32
+	// Scale is the size of a tile relative to the region. The zero value is treated as 1.
+	Scale float32
+	// OffsetX and OffsetY scroll the tiles, in texture pixels.
+	// Increasing them every frame produces scrolling backgrounds, conveyor belts or water.
+	OffsetX, OffsetY float32
+	WrapMode         WrapMode
+	Tint             color.Color
+	// Alpha scales the opacity of the tiles. A nil Alpha leaves them fully opaque.
+	Alpha *float32
+}
+
+// RenderTiledTexture fills a rectangle with repeated copies of a texture region.
+func RenderTiledTexture(textureHandle uint32, options *TiledTextureRenderOptions) {
+	ensureSetupCompletion()
+	banana.graphicsBackend.RenderTiledTexture(textureHandle, &graphics.TiledTextureRenderOptions{
+		X:          options.X,
+		Y:          options.Y,
+		Width:      options.Width,
+		Height:     options.Height,
+		RectX:      options.RectX,
+		RectY:      options.RectY,
+		RectWidth:  options.RectWidth,
+		RectHeight: options.RectHeight,
+		Scale:      options.Scale,
+		OffsetX:    options.OffsetX,
+		OffsetY:    options.OffsetY,
+		WrapMode:   graphics.WrapMode(options.WrapMode),
+		Tint:       options.Tint,
+		Alpha:      options.Alpha,
+	})
+}